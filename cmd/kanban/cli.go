@@ -0,0 +1,490 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"kanban/internal/board"
+	"kanban/internal/card"
+	"kanban/internal/column"
+	"kanban/internal/fs"
+	"kanban/internal/history"
+)
+
+// subcommands drive the board headlessly so it can be scripted without the TUI.
+var subcommands = map[string]func(args []string) error{
+	"add":     runAdd,
+	"move":    runMove,
+	"done":    runDone,
+	"archive": runArchive,
+	"ls":      runLs,
+	"show":    runShow,
+	"doctor":  runDoctor,
+}
+
+func newFlagSet(name, usage string) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	dir := flags.String("C", "", "run as if started in `dir`")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: kanban %s\n", usage)
+		flags.PrintDefaults()
+	}
+	return flags, dir
+}
+
+// parseFlags parses args like flags.Parse, but also reads flags that follow
+// the other arguments, as in "show hello -json". It returns the other
+// arguments; everything after "--" is one of them.
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		left := flags.Args()
+		if len(left) == 0 {
+			return rest, nil
+		}
+		if i := len(args) - len(left); i > 0 && args[i-1] == "--" {
+			return append(rest, left...), nil
+		}
+		rest, args = append(rest, left[0]), left[1:]
+	}
+}
+
+// openBoard changes into dir (if given) and loads the board found there.
+func openBoard(dir string) (board.Board, error) {
+	if dir != "" {
+		if err := os.Chdir(dir); err != nil {
+			return board.Board{}, err
+		}
+	}
+	return loadBoard()
+}
+
+// lockBoard is openBoard for the subcommands that change the board. They take
+// the board's lock first, so they cannot race the TUI or each other; the
+// caller releases it.
+func lockBoard(dir string) (board.Board, *fs.Lock, error) {
+	if dir != "" {
+		if err := os.Chdir(dir); err != nil {
+			return board.Board{}, nil, err
+		}
+	}
+	if _, err := os.Stat(fs.BoardFileName); err != nil {
+		return board.Board{}, nil, fmt.Errorf("no kanban board (%s) found: %w", fs.BoardFileName, err)
+	}
+	lock, err := fs.AcquireLock()
+	if err != nil {
+		var locked *fs.LockedError
+		if errors.As(err, &locked) {
+			return board.Board{}, nil, fmt.Errorf("%w; close it and try again", err)
+		}
+		return board.Board{}, nil, err
+	}
+	b, err := loadBoard()
+	if err != nil {
+		lock.Release()
+		return board.Board{}, nil, err
+	}
+	return b, lock, nil
+}
+
+func loadBoard() (board.Board, error) {
+	b, err := fs.LoadBoard()
+	if err != nil {
+		return board.Board{}, err
+	}
+	if len(b.Columns) == 0 {
+		return board.Board{}, fmt.Errorf("no kanban board (%s) found in %s", fs.BoardFileName, b.Path)
+	}
+	return b, nil
+}
+
+func runAdd(args []string) error {
+	flags, dir := newFlagSet("add", "add [-C dir] [-c column] [-append] <title>")
+	colName := flags.String("c", "", "add the card to `column` (default: first column)")
+	appendCard := flags.Bool("append", false, "add the card at the bottom of the column")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	title := strings.TrimSpace(strings.Join(args, " "))
+	if title == "" {
+		flags.Usage()
+		return fmt.Errorf("missing card title")
+	}
+
+	b, lock, err := lockBoard(*dir)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	col := &b.Columns[0]
+	if *colName != "" {
+		if col = b.FindColumn(*colName); col == nil {
+			return fmt.Errorf("no column named %q", *colName)
+		}
+	}
+
+	before := b.DeepCopy()
+	newCard, err := fs.CreateCard(*col, title)
+	if err != nil {
+		return err
+	}
+	if *appendCard {
+		col.Cards = append(col.Cards, newCard)
+	} else {
+		col.Cards = append([]card.Card{newCard}, col.Cards...)
+	}
+	if err := fs.WriteBoard(b); err != nil {
+		return err
+	}
+	journal(before, b, "create card")
+
+	fmt.Println(newCard.UUID)
+	return nil
+}
+
+func runMove(args []string) error {
+	flags, dir := newFlagSet("move", "move [-C dir] [-f] <card> <column>")
+	force := flags.Bool("f", false, "ignore the destination's WIP limit")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		flags.Usage()
+		return fmt.Errorf("move takes a card and a destination column")
+	}
+
+	b, lock, err := lockBoard(*dir)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	destCol := b.FindColumn(args[1])
+	if destCol == nil {
+		return fmt.Errorf("no column named %q", args[1])
+	}
+	return moveCardsTo(&b, args[:1], destCol, *force, "move")
+}
+
+func runDone(args []string) error {
+	flags, dir := newFlagSet("done", "done [-C dir] [-f] <card>...")
+	force := flags.Bool("f", false, "ignore the done column's WIP limit")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		flags.Usage()
+		return fmt.Errorf("missing card")
+	}
+
+	b, lock, err := lockBoard(*dir)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	state, err := fs.LoadState()
+	if err != nil {
+		return fmt.Errorf("could not load state: %w", err)
+	}
+	done := state.DoneColumn
+	if done == "" {
+		cfg, err := fs.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("could not load config: %w", err)
+		}
+		done = cfg.DoneColumn
+	}
+	if done == "" {
+		return fmt.Errorf("done column is not set; use `:set done` in the TUI or done_column in the config")
+	}
+	destCol := b.FindColumn(done)
+	if destCol == nil {
+		return fmt.Errorf("done column %q no longer exists", done)
+	}
+	return moveCardsTo(&b, args, destCol, *force, "done")
+}
+
+func runArchive(args []string) error {
+	flags, dir := newFlagSet("archive", "archive [-C dir] <card>...")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		flags.Usage()
+		return fmt.Errorf("missing card")
+	}
+
+	b, lock, err := lockBoard(*dir)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	if err := os.MkdirAll(b.Archived.Path, 0755); err != nil {
+		return fmt.Errorf("could not create archive dir: %w", err)
+	}
+	return moveCardsTo(&b, args, &b.Archived, true, "archive")
+}
+
+// moveCardsTo moves every referenced card to the bottom of destCol and
+// rewrites kanban.md. Unless force is set, nothing is moved if the cards would
+// push destCol past its WIP limit. The move is journaled as op so it can be
+// undone from the TUI.
+func moveCardsTo(b *board.Board, refs []string, destCol *column.Column, force bool, op string) error {
+	// Why: Every ref is resolved before any file is touched, so that a typo in
+	// the last one does not leave the first ones moved behind kanban.md's back.
+	var uuids []string
+	seen := make(map[string]struct{})
+	for _, ref := range refs {
+		srcCol, idx, err := b.FindCard(ref)
+		if err != nil {
+			return err
+		}
+		uuid := srcCol.Cards[idx].UUID
+		if _, ok := seen[uuid]; ok || srcCol.Title == destCol.Title {
+			continue
+		}
+		seen[uuid] = struct{}{}
+		uuids = append(uuids, uuid)
+	}
+	if len(uuids) == 0 {
+		return nil
+	}
+	if !force && destCol.WouldExceedLimit(len(uuids)) {
+		return fmt.Errorf("%q is at its WIP limit of %d; use -f to force", destCol.Title, destCol.Limit)
+	}
+
+	before := b.DeepCopy()
+	var moveErr error
+	for _, uuid := range uuids {
+		srcCol, idx, err := b.FindCard(uuid)
+		if err != nil {
+			moveErr = err
+			break
+		}
+		c := srcCol.Cards[idx]
+		if err := fs.MoveCard(&c, *destCol); err != nil {
+			moveErr = fmt.Errorf("could not move %q: %w", c.Title, err)
+			break
+		}
+		srcCol.RemoveAt(idx)
+		destCol.Cards = append(destCol.Cards, c)
+	}
+	// Why: kanban.md is written even after a failed move, so that it points
+	// at the files of the cards that were moved before it.
+	if err := fs.WriteBoard(*b); err != nil {
+		return err
+	}
+	journal(before, *b, op)
+	return moveErr
+}
+
+// journal records the change from before to after in the board's undo
+// journal so `u` in the TUI reverts it. Failing to journal does not fail the
+// command. The caller holds the board's lock, which guards the journal too.
+func journal(before, after board.Board, op string) {
+	h, err := history.Load(filepath.Join(fs.DataDirName, fs.JournalFileName))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not load undo journal: %v\n", err)
+		return
+	}
+	h.Push(before, op)
+	if err := h.Save(after); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not save undo journal: %v\n", err)
+	}
+}
+
+func runLs(args []string) error {
+	flags, dir := newFlagSet("ls", "ls [-C dir] [-a] [-json | -ndjson] [column]")
+	showArchived := flags.Bool("a", false, "include archived cards")
+	asJSON := flags.Bool("json", false, "print the board as a JSON document")
+	asNDJSON := flags.Bool("ndjson", false, "print one JSON card per line")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	b, err := openBoard(*dir)
+	if err != nil {
+		return err
+	}
+
+	cols := b.Columns
+	if *showArchived {
+		cols = append(cols, b.Archived)
+	}
+	if len(args) > 0 {
+		col := b.FindColumn(strings.Join(args, " "))
+		if col == nil {
+			return fmt.Errorf("no column named %q", strings.Join(args, " "))
+		}
+		cols = []column.Column{*col}
+	}
+
+	switch {
+	case *asJSON:
+		return writeBoardJSON(os.Stdout, b.Path, cols)
+	case *asNDJSON:
+		return writeCardsNDJSON(os.Stdout, cols)
+	}
+
+	for i, col := range cols {
+		if i > 0 {
+			fmt.Println()
+		}
+		if col.Limit > 0 {
+			fmt.Printf("# %s (%d/%d)\n", col.Title, col.CardCount(), col.Limit)
+		} else {
+			fmt.Printf("# %s (%d)\n", col.Title, col.CardCount())
+		}
+		for _, c := range col.Cards {
+			fmt.Printf("%s  %s\n", shortID(c.UUID), c.Title)
+		}
+	}
+	return nil
+}
+
+func runShow(args []string) error {
+	flags, dir := newFlagSet("show", "show [-C dir] [-json] <card>")
+	asJSON := flags.Bool("json", false, "print the card as JSON")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		flags.Usage()
+		return fmt.Errorf("show takes exactly one card")
+	}
+
+	b, err := openBoard(*dir)
+	if err != nil {
+		return err
+	}
+
+	col, idx, err := b.FindCard(args[0])
+	if err != nil {
+		return err
+	}
+	c := col.Cards[idx]
+
+	if *asJSON {
+		return writeCardJSON(os.Stdout, c, col.Title)
+	}
+
+	fmt.Printf("Title:    %s\n", c.Title)
+	fmt.Printf("UUID:     %s\n", c.UUID)
+	fmt.Printf("Column:   %s\n", col.Title)
+	fmt.Printf("Path:     %s\n", c.Path)
+	if c.HasLink() {
+		fmt.Printf("Link:     %s\n", c.Link)
+	}
+	if len(c.Tags) > 0 {
+		fmt.Printf("Tags:     %s\n", strings.Join(c.Tags, ", "))
+	}
+	if c.HasDue() {
+		fmt.Printf("Due:      %s\n", c.Due)
+	}
+	if c.Priority != "" {
+		fmt.Printf("Priority: %s\n", c.Priority)
+	}
+	if c.Assignee != "" {
+		fmt.Printf("Assignee: %s\n", c.Assignee)
+	}
+	if c.Lane != "" {
+		fmt.Printf("Lane:     %s\n", c.Lane)
+	}
+	if done, total := c.ChecklistProgress(); total > 0 {
+		fmt.Printf("Checklist: %d/%d\n", done, total)
+	}
+	fmt.Printf("Created:  %s\n", c.CreatedAt.Format("2006-01-02 15:04"))
+	fmt.Printf("Modified: %s\n", c.ModifiedAt.Format("2006-01-02 15:04"))
+	if c.HasContent() {
+		fmt.Printf("\n%s\n", c.Content)
+	}
+	return nil
+}
+
+func runDoctor(args []string) error {
+	flags, dir := newFlagSet("doctor", "doctor [-C dir] [-fix]")
+	fix := flags.Bool("fix", false, "re-link orphaned cards and move card files into place")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if *dir != "" {
+		if err := os.Chdir(*dir); err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Stat(fs.BoardFileName); err != nil {
+		return fmt.Errorf("no kanban board (%s) found: %w", fs.BoardFileName, err)
+	}
+
+	// Why: A running instance keeps the cards deleted in it until it exits, so
+	// they look like orphans, and fixing would race with its writes.
+	lock, err := fs.AcquireLock()
+	var locked *fs.LockedError
+	switch {
+	case errors.As(err, &locked) && *fix:
+		return fmt.Errorf("%w; use :fsck! in it instead", err)
+	case errors.As(err, &locked):
+		fmt.Fprintf(os.Stderr, "note: %v; cards deleted there show up as orphans until it exits\n", err)
+	case err != nil:
+		return err
+	}
+	defer lock.Release()
+
+	problems, err := fs.CheckBoard()
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		fmt.Println("No problems found")
+		return nil
+	}
+
+	fixable := 0
+	for _, p := range problems {
+		fmt.Println(p)
+		if p.Fixable {
+			fixable++
+		}
+	}
+	if !*fix {
+		if fixable > 0 {
+			fmt.Printf("\n%d of %d problem(s) can be fixed with `kanban doctor -fix`\n", fixable, len(problems))
+		}
+		return fmt.Errorf("%d problem(s) found", len(problems))
+	}
+
+	fixed, err := fs.FixBoard(problems)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nFixed %d of %d problem(s)\n", fixed, len(problems))
+	if fixed < len(problems) {
+		return fmt.Errorf("%d problem(s) need fixing by hand", len(problems)-fixed)
+	}
+	return nil
+}
+
+func shortID(uuid string) string {
+	if len(uuid) > 8 {
+		return uuid[:8]
+	}
+	return uuid
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+
+	"kanban/internal/card"
+	"kanban/internal/column"
+)
+
+// boardRecord is the JSON shape of `kanban ls --json`.
+type boardRecord struct {
+	Path    string          `json:"path"`
+	Columns []column.Column `json:"columns"`
+}
+
+// cardRecord is a card annotated with the column it lives in, used for
+// NDJSON lines and `kanban show --json`.
+type cardRecord struct {
+	card.Card
+	Column string `json:"column"`
+}
+
+func writeBoardJSON(w io.Writer, path string, cols []column.Column) error {
+	rec := boardRecord{Path: path, Columns: make([]column.Column, len(cols))}
+	for i, col := range cols {
+		// Why: Emit [] rather than null for empty columns so consumers like jq
+		// can iterate without guarding.
+		if col.Cards == nil {
+			col.Cards = []card.Card{}
+		}
+		rec.Columns[i] = col
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rec)
+}
+
+func writeCardsNDJSON(w io.Writer, cols []column.Column) error {
+	enc := json.NewEncoder(w)
+	for _, col := range cols {
+		for _, c := range col.Cards {
+			if err := enc.Encode(cardRecord{Card: c, Column: col.Title}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeCardJSON(w io.Writer, c card.Card, colTitle string) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(cardRecord{Card: c, Column: colTitle})
+}
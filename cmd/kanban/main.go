@@ -2,6 +2,8 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,6 +16,17 @@ import (
 
 func main() {
 	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				if errors.Is(err, flag.ErrHelp) {
+					os.Exit(0)
+				}
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		if os.Args[1] == "--main" {
 			if len(os.Args) < 3 {
 				fmt.Fprintln(os.Stderr, "error: --main requires at least one path argument")
@@ -103,8 +116,11 @@ func main() {
 			fmt.Fprintf(os.Stderr, "error during cleanup: %v\n", err)
 		}
 
-		s := m.State()
-		if err := fs.SaveState(s.FocusedColumn, s.FocusedCard, s.DoneColumn, s.ShowHidden); err != nil {
+		// Why: state.json belongs to the instance holding the lock.
+		if m.ReadOnly() {
+			return
+		}
+		if err := fs.SaveState(m.State()); err != nil {
 			fmt.Fprintf(os.Stderr, "could not save state: %v\n", err)
 			os.Exit(1)
 		}
@@ -1,16 +1,22 @@
 package board
 
 import (
+	"fmt"
+	"strings"
+
 	"kanban/internal/card"
 	"kanban/internal/column"
 )
 
 type Board struct {
 	// Path is the root directory containing kanban.md
-	Path    string
-	Columns []column.Column
-	Archived column.Column
-	Trash   []card.Card
+	Path    string          `json:"path"`
+	Columns []column.Column `json:"columns"`
+	Archived column.Column  `json:"archived"`
+	Trash   []card.Card     `json:"trash,omitempty"`
+	// Preamble is the text of kanban.md before the first column, such as
+	// front matter or an introduction.
+	Preamble []string `json:"preamble,omitempty"`
 }
 
 func New(path string, columns []column.Column) Board {
@@ -19,21 +25,19 @@ func New(path string, columns []column.Column) Board {
 
 func (b *Board) DeepCopy() Board {
 	newBoard := Board{
-		Path: b.Path,
+		Path:     b.Path,
+		Preamble: b.Preamble,
 	}
 
 	newBoard.Columns = make([]column.Column, len(b.Columns))
 	for i, col := range b.Columns {
-		newCol := column.Column{Title: col.Title, Path: col.Path}
+		newCol := col
 		newCol.Cards = make([]card.Card, len(col.Cards))
 		copy(newCol.Cards, col.Cards)
 		newBoard.Columns[i] = newCol
 	}
 
-	newArchived := column.Column{
-		Title: b.Archived.Title,
-		Path:  b.Archived.Path,
-	}
+	newArchived := b.Archived
 	newArchived.Cards = make([]card.Card, len(b.Archived.Cards))
 	copy(newArchived.Cards, b.Archived.Cards)
 	newBoard.Archived = newArchived
@@ -43,3 +47,75 @@ func (b *Board) DeepCopy() Board {
 
 	return newBoard
 }
+
+// FindColumn returns the column with the given title, including the archive.
+// An exact match wins over a case-insensitive one.
+func (b *Board) FindColumn(title string) *column.Column {
+	for i := range b.Columns {
+		if b.Columns[i].Title == title {
+			return &b.Columns[i]
+		}
+	}
+	if b.Archived.Title == title {
+		return &b.Archived
+	}
+	for i := range b.Columns {
+		if strings.EqualFold(b.Columns[i].Title, title) {
+			return &b.Columns[i]
+		}
+	}
+	if strings.EqualFold(b.Archived.Title, title) {
+		return &b.Archived
+	}
+	return nil
+}
+
+// FindCard resolves ref to a single card. ref may be a full UUID, a
+// case-insensitive card title, a unique UUID prefix or a unique part of a
+// title, tried in that order.
+func (b *Board) FindCard(ref string) (*column.Column, int, error) {
+	if ref == "" {
+		return nil, 0, fmt.Errorf("empty card reference")
+	}
+
+	type hit struct {
+		col   *column.Column
+		index int
+	}
+	var byTitle, byPrefix, bySubstring []hit
+	lowerRef := strings.ToLower(ref)
+
+	cols := make([]*column.Column, 0, len(b.Columns)+1)
+	for i := range b.Columns {
+		cols = append(cols, &b.Columns[i])
+	}
+	cols = append(cols, &b.Archived)
+
+	for _, col := range cols {
+		for i, c := range col.Cards {
+			if c.UUID == ref {
+				return col, i, nil
+			}
+			if strings.HasPrefix(c.UUID, ref) {
+				byPrefix = append(byPrefix, hit{col, i})
+			}
+			if strings.EqualFold(c.Title, ref) {
+				byTitle = append(byTitle, hit{col, i})
+			} else if strings.Contains(strings.ToLower(c.Title), lowerRef) {
+				bySubstring = append(bySubstring, hit{col, i})
+			}
+		}
+	}
+
+	for _, hits := range [][]hit{byTitle, byPrefix, bySubstring} {
+		switch len(hits) {
+		case 0:
+			continue
+		case 1:
+			return hits[0].col, hits[0].index, nil
+		default:
+			return nil, 0, fmt.Errorf("card reference %q is ambiguous (%d matches)", ref, len(hits))
+		}
+	}
+	return nil, 0, fmt.Errorf("no card matches %q", ref)
+}
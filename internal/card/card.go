@@ -1,16 +1,45 @@
 package card
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DueDateLayout is the format of the due date stored in front matter.
+const DueDateLayout = "2006-01-02"
+
+// Priorities lists the valid priorities from most to least urgent.
+var Priorities = []string{"P0", "P1", "P2", "P3"}
+
+var priorityAliases = map[string]string{
+	"critical": "P0",
+	"high":     "P1",
+	"medium":   "P2",
+	"low":      "P3",
+}
 
 type Card struct {
-	UUID       string    `yaml:"-"` // Derived from filename
-	Path       string    `yaml:"-"`
-	Title      string    `yaml:"title"`
-	Link       string    `yaml:"link,omitempty"`
-	Content    string    `yaml:"-"`
-	CreatedAt  time.Time `yaml:"createdAt"`
-	ModifiedAt time.Time `yaml:"modifiedAt"`
-	Size       int64     `yaml:"-"` // File size in bytes
+	UUID       string    `yaml:"-" json:"uuid"` // Derived from filename
+	Path       string    `yaml:"-" json:"path"`
+	Title      string    `yaml:"title" json:"title"`
+	Link       string    `yaml:"link,omitempty" json:"link,omitempty"`
+	Tags       []string  `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Due        string    `yaml:"due,omitempty" json:"due,omitempty"`           // DueDateLayout
+	Priority   string    `yaml:"priority,omitempty" json:"priority,omitempty"` // One of Priorities
+	Assignee   string    `yaml:"assignee,omitempty" json:"assignee,omitempty"`
+	Lane       string    `yaml:"lane,omitempty" json:"lane,omitempty"` // Swimlane when grouping by lane
+	Content    string    `yaml:"-" json:"content"`
+	CreatedAt  time.Time `yaml:"createdAt" json:"createdAt"`
+	ModifiedAt time.Time `yaml:"modifiedAt" json:"modifiedAt"`
+	Size       int64     `yaml:"-" json:"size"` // File size in bytes
+	// Annotation holds the lines following the card's entry in kanban.md,
+	// such as indented sub-items. They move with the card.
+	Annotation []string `yaml:"-" json:"annotation,omitempty"`
+	// Checklist holds the task list items of Content, see ParseChecklist.
+	Checklist []ChecklistItem `yaml:"-" json:"checklist,omitempty"`
 }
 
 func New(title string) Card {
@@ -25,3 +54,133 @@ func (c Card) HasContent() bool {
 func (c Card) HasLink() bool {
 	return c.Link != ""
 }
+
+func (c Card) HasTag(tag string) bool {
+	for _, t := range c.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTag adds tag unless the card already carries it and reports whether the
+// card changed.
+func (c *Card) AddTag(tag string) bool {
+	if tag == "" || c.HasTag(tag) {
+		return false
+	}
+	c.Tags = append(c.Tags, tag)
+	return true
+}
+
+// RemoveTag removes tag and reports whether the card changed.
+func (c *Card) RemoveTag(tag string) bool {
+	kept := make([]string, 0, len(c.Tags))
+	for _, t := range c.Tags {
+		if !strings.EqualFold(t, tag) {
+			kept = append(kept, t)
+		}
+	}
+	changed := len(kept) != len(c.Tags)
+	c.Tags = kept
+	return changed
+}
+
+func (c Card) HasDue() bool {
+	return c.Due != ""
+}
+
+// DueIn returns the number of calendar days from now until the card is due,
+// negative once it is overdue. ok is false if the card has no valid due date.
+func (c Card) DueIn(now time.Time) (days int, ok bool) {
+	if c.Due == "" {
+		return 0, false
+	}
+	due, err := time.ParseInLocation(DueDateLayout, c.Due, now.Location())
+	if err != nil {
+		return 0, false
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return int(math.Round(due.Sub(today).Hours() / 24)), true
+}
+
+// ParseDue turns a user supplied date into DueDateLayout. It accepts absolute
+// dates (2026-11-01), "today", "tomorrow" and offsets such as +3d, +2w or +1m.
+func ParseDue(s string, now time.Time) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "today":
+		return now.Format(DueDateLayout), nil
+	case "tomorrow":
+		return now.AddDate(0, 0, 1).Format(DueDateLayout), nil
+	}
+
+	if strings.HasPrefix(s, "+") && len(s) > 2 {
+		n, err := strconv.Atoi(s[1 : len(s)-1])
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("invalid due offset: %s", s)
+		}
+		switch s[len(s)-1] {
+		case 'd':
+			return now.AddDate(0, 0, n).Format(DueDateLayout), nil
+		case 'w':
+			return now.AddDate(0, 0, 7*n).Format(DueDateLayout), nil
+		case 'm':
+			return now.AddDate(0, n, 0).Format(DueDateLayout), nil
+		}
+		return "", fmt.Errorf("invalid due offset unit in %s (use d, w or m)", s)
+	}
+
+	t, err := time.ParseInLocation(DueDateLayout, s, now.Location())
+	if err != nil {
+		return "", fmt.Errorf("invalid due date: %s (use YYYY-MM-DD or +Nd)", s)
+	}
+	return t.Format(DueDateLayout), nil
+}
+
+// PriorityRank orders cards by urgency: 0 for P0, increasing from there, and
+// len(Priorities) for cards without a priority.
+func (c Card) PriorityRank() int {
+	for i, p := range Priorities {
+		if strings.EqualFold(c.Priority, p) {
+			return i
+		}
+	}
+	return len(Priorities)
+}
+
+// ParsePriority accepts P0-P3, 0-3 or critical/high/medium/low and returns
+// the canonical form. "none" and "" clear the priority.
+func ParsePriority(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || s == "none" {
+		return "", nil
+	}
+	if p, ok := priorityAliases[s]; ok {
+		return p, nil
+	}
+	for _, p := range Priorities {
+		if s == strings.ToLower(p) || s == p[1:] {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("invalid priority: %s (use P0-P3 or none)", s)
+}
+
+// BumpPriority raises (delta < 0, towards P0) or lowers the priority. Lowering
+// past the last priority clears it and raising an unset priority starts at
+// the lowest one. It reports whether the card changed.
+func (c *Card) BumpPriority(delta int) bool {
+	rank := c.PriorityRank() + delta
+	if rank < 0 {
+		rank = 0
+	}
+	next := ""
+	if rank < len(Priorities) {
+		next = Priorities[rank]
+	}
+	changed := next != c.Priority
+	c.Priority = next
+	return changed
+}
@@ -0,0 +1,79 @@
+package card
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDue(t *testing.T) {
+	now := time.Date(2026, time.January, 15, 18, 30, 0, 0, time.Local)
+	tests := []struct {
+		in, want string
+		err      bool
+	}{
+		{in: "today", want: "2026-01-15"},
+		{in: " Tomorrow ", want: "2026-01-16"},
+		{in: "+0d", want: "2026-01-15"},
+		{in: "+3d", want: "2026-01-18"},
+		{in: "+20d", want: "2026-02-04"},
+		{in: "+2w", want: "2026-01-29"},
+		{in: "+1m", want: "2026-02-15"},
+		{in: "+12m", want: "2027-01-15"},
+		{in: "2026-03-01", want: "2026-03-01"},
+		{in: "+3", err: true},
+		{in: "+d", err: true},
+		{in: "+-1d", err: true},
+		{in: "+3y", err: true},
+		{in: "2026-02-30", err: true},
+		{in: "next week", err: true},
+		{in: "", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDue(tt.in, now)
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParseDue(%q) = %q; want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseDue(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestBumpPriority(t *testing.T) {
+	tests := []struct {
+		from  string
+		delta int
+		want  string
+	}{
+		{"", -1, "P3"},
+		{"P3", -1, "P2"},
+		{"P1", -1, "P0"},
+		{"P0", -1, "P0"},
+		{"P0", -3, "P0"},
+		{"P0", 1, "P1"},
+		{"P2", 1, "P3"},
+		{"P3", 1, ""},
+		{"", 1, ""},
+		{"p2", 1, "P3"},
+		{"bogus", -1, "P3"},
+	}
+	for _, tt := range tests {
+		c := Card{Priority: tt.from}
+		changed := c.BumpPriority(tt.delta)
+		if c.Priority != tt.want || changed != (tt.want != tt.from) {
+			t.Errorf("BumpPriority(%d) from %q = %q, %v; want %q", tt.delta, tt.from, c.Priority, changed, tt.want)
+		}
+	}
+
+	// Why: Lowering past P3 clears the priority and raising it again wraps
+	// back to P3.
+	c := Card{Priority: "P3"}
+	c.BumpPriority(1)
+	c.BumpPriority(-1)
+	if c.Priority != "P3" {
+		t.Errorf("P3 lowered and raised = %q; want P3", c.Priority)
+	}
+}
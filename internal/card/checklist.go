@@ -0,0 +1,74 @@
+package card
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ChecklistItem is a task list item ("- [ ] text" or "- [x] text") in the
+// body of a card.
+type ChecklistItem struct {
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+	// Line is the index of the item's line in Content.
+	Line int `json:"-"`
+}
+
+var checklistItemRegex = regexp.MustCompile(`^(\s*(?:[-*+]|\d{1,9}[.)])\s+\[)([ xX])(\](?:\s+(.*))?)$`)
+
+// ParseChecklist returns the task list items of content, at any nesting
+// level, skipping fenced code blocks.
+func ParseChecklist(content string) []ChecklistItem {
+	var items []ChecklistItem
+	fence := ""
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		if m := checklistItemRegex.FindStringSubmatch(line); m != nil {
+			items = append(items, ChecklistItem{Text: strings.TrimSpace(m[4]), Done: m[2] != " ", Line: i})
+		}
+	}
+	return items
+}
+
+// ChecklistProgress returns how many checklist items are done, out of how
+// many.
+func (c Card) ChecklistProgress() (done, total int) {
+	for _, item := range c.Checklist {
+		if item.Done {
+			done++
+		}
+	}
+	return done, len(c.Checklist)
+}
+
+// ToggleChecklistItem checks or unchecks checklist item i in Content and
+// reports whether it exists.
+func (c *Card) ToggleChecklistItem(i int) bool {
+	if i < 0 || i >= len(c.Checklist) {
+		return false
+	}
+	lines := strings.Split(c.Content, "\n")
+	item := c.Checklist[i]
+	m := checklistItemRegex.FindStringSubmatch(lines[item.Line])
+	if m == nil {
+		return false
+	}
+	mark := "x"
+	if item.Done {
+		mark = " "
+	}
+	lines[item.Line] = m[1] + mark + m[3]
+	c.Content = strings.Join(lines, "\n")
+	c.Checklist = ParseChecklist(c.Content)
+	return true
+}
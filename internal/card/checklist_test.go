@@ -0,0 +1,64 @@
+package card
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseChecklist(t *testing.T) {
+	tests := []struct {
+		name, content string
+		want          []ChecklistItem
+	}{
+		{"none", "Just text\n- a list item", nil},
+		{"items", "- [ ] a\n* [x] b\n+ [X] c\n1. [ ] d\n2) [ ]", []ChecklistItem{
+			{Text: "a", Line: 0}, {Text: "b", Done: true, Line: 1}, {Text: "c", Done: true, Line: 2}, {Text: "d", Line: 3}, {Line: 4},
+		}},
+		{"nested", "- [ ] parent\n  - [x] child\n\t- [ ] tabbed", []ChecklistItem{
+			{Text: "parent", Line: 0}, {Text: "child", Done: true, Line: 1}, {Text: "tabbed", Line: 2},
+		}},
+		{"fences", "- [ ] before\n```\n- [ ] code\n```\n~~~md\n- [x] more code\n```\n~~~\n- [x] after", []ChecklistItem{
+			{Text: "before", Line: 0}, {Text: "after", Done: true, Line: 8},
+		}},
+		{"crlf", "- [ ] a\r\n- [x] b\r\n", []ChecklistItem{
+			{Text: "a", Line: 0}, {Text: "b", Done: true, Line: 1},
+		}},
+		{"not items", "-[ ] a\n- [] b\n- [y] c\n- [ ]d\n[ ] e", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseChecklist(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseChecklist() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToggleChecklistItem(t *testing.T) {
+	tests := []struct {
+		name, content string
+		item          int
+		want          string
+		ok            bool
+	}{
+		{"check", "- [ ] a\n- [ ] b", 1, "- [ ] a\n- [x] b", true},
+		{"uncheck", "* [X] a", 0, "* [ ] a", true},
+		{"nested", "- [ ] a\n  1. [ ] b", 1, "- [ ] a\n  1. [x] b", true},
+		{"after fence", "```\n- [ ] code\n```\n- [ ] a", 0, "```\n- [ ] code\n```\n- [x] a", true},
+		{"crlf", "- [ ] a\r\n- [x] b\r\n", 1, "- [ ] a\r\n- [ ] b\r\n", true},
+		{"out of range", "- [ ] a", 1, "- [ ] a", false},
+		{"negative", "- [ ] a", -1, "- [ ] a", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Card{Content: tt.content, Checklist: ParseChecklist(tt.content)}
+			ok := c.ToggleChecklistItem(tt.item)
+			if ok != tt.ok || c.Content != tt.want {
+				t.Errorf("ToggleChecklistItem(%d) = %v, content %q; want %v, %q", tt.item, ok, c.Content, tt.ok, tt.want)
+			}
+			if !reflect.DeepEqual(c.Checklist, ParseChecklist(c.Content)) {
+				t.Errorf("Checklist = %+v; not updated for %q", c.Checklist, c.Content)
+			}
+		})
+	}
+}
@@ -3,9 +3,15 @@ package column
 import "kanban/internal/card"
 
 type Column struct {
-	Title string
-	Path  string
-	Cards []card.Card
+	Title string      `json:"title"`
+	Path  string      `json:"path"`
+	Cards []card.Card `json:"cards"`
+	// Limit is the work-in-progress limit; zero means unlimited.
+	Limit int `json:"limit,omitempty"`
+	// Description and Notes are the lines of kanban.md before and after the
+	// column's card list, kept as written.
+	Description []string `json:"description,omitempty"`
+	Notes       []string `json:"notes,omitempty"`
 }
 
 func New(title, path string, cards ...card.Card) Column {
@@ -19,3 +25,20 @@ func New(title, path string, cards ...card.Card) Column {
 func (c Column) CardCount() int {
 	return len(c.Cards)
 }
+
+// WouldExceedLimit reports whether adding incoming cards would push the column
+// past its WIP limit.
+func (c Column) WouldExceedLimit(incoming int) bool {
+	return c.Limit > 0 && len(c.Cards)+incoming > c.Limit
+}
+
+func (c Column) OverLimit() bool {
+	return c.WouldExceedLimit(0)
+}
+
+// RemoveAt removes and returns the card at index.
+func (c *Column) RemoveAt(index int) card.Card {
+	removed := c.Cards[index]
+	c.Cards = append(c.Cards[:index:index], c.Cards[index+1:]...)
+	return removed
+}
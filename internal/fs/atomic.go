@@ -0,0 +1,122 @@
+// internal/fs/atomic.go
+package fs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// BackupDirName holds previous versions of kanban.md inside the data directory.
+	BackupDirName = ".backups"
+	// MaxBoardBackups is how many previous versions of kanban.md are kept.
+	MaxBoardBackups = 10
+)
+
+// WriteFileAtomic writes data to path so that readers and crashes only ever
+// see the old or the new contents: it writes a temporary file next to path,
+// syncs it to disk and renames it over path. An existing file keeps its mode;
+// perm applies to new files only.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	// Why: Renaming over a symlink would replace the link instead of the file
+	// it points to.
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	// Why: Once the rename succeeded this is a no-op; before that it stops
+	// failed writes from leaving temporary files behind.
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir makes a rename in dir durable. Not every platform can sync a
+// directory, so failures are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+// backupBoard copies the current kanban.md into the backup directory and
+// prunes all but the newest MaxBoardBackups copies.
+func backupBoard() error {
+	data, err := os.ReadFile(BoardFileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	dir := filepath.Join(DataDirName, BackupDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("kanban-%s.md", time.Now().UTC().Format("20060102T150405.000000000"))
+	if err := WriteFileAtomic(filepath.Join(dir, name), data, 0644); err != nil {
+		return err
+	}
+	return pruneBackups(dir)
+}
+
+func pruneBackups(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var backups []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), "kanban-") && strings.HasSuffix(e.Name(), ".md") {
+			backups = append(backups, e.Name())
+		}
+	}
+	// The timestamps in the names sort chronologically.
+	sort.Strings(backups)
+	for len(backups) > MaxBoardBackups {
+		if err := os.Remove(filepath.Join(dir, backups[0])); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
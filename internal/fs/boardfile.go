@@ -0,0 +1,378 @@
+// internal/fs/boardfile.go
+package fs
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"kanban/internal/board"
+	"kanban/internal/column"
+)
+
+// boardFile is kanban.md as written, before any card file has been read.
+// Besides the columns and their card entries it keeps every other line, so
+// that rewriting the file only replaces the card lists.
+//
+// The runs of blank lines between the parts of the file are kept as read too.
+// A nil run is one the file did not have, such as the gap before a column
+// added since, and is written as a single blank line.
+type boardFile struct {
+	// preamble is everything before the first column header, and
+	// preambleGap the blank lines that end it.
+	preamble    []string
+	preambleGap []string
+	columns     []boardFileColumn
+	// end is the blank lines at the end of the file.
+	end []string
+}
+
+type boardFileColumn struct {
+	title string
+	limit int
+	// header is the header line as read.
+	header string
+	// description and notes are the lines before the first and after the
+	// last card entry.
+	description []string
+	notes       []string
+	links       []cardLink
+	// lead, listGap and notesGap are the blank lines before the description,
+	// the card list and the notes; gap those before the next column.
+	lead, listGap, notesGap, gap []string
+}
+
+// cardLink is a card entry: the title shown in kanban.md, the path of the card
+// file and the lines up to the next entry.
+type cardLink struct {
+	title      string
+	path       string
+	annotation []string
+	// line is the entry as read.
+	line string
+}
+
+// uuid returns the UUID of the card the entry points to.
+func (l cardLink) uuid() string {
+	return strings.TrimSuffix(filepath.Base(l.path), ".md")
+}
+
+func (c boardFileColumn) dir() string {
+	return columnDir(c.title, c.legacyName())
+}
+
+// legacyName returns the title as written in the header, which older versions
+// named the column directory after without unescaping it.
+func (c boardFileColumn) legacyName() string {
+	if strings.HasPrefix(c.header, "# ") {
+		text, _ := splitColumnHeader(strings.TrimSpace(strings.TrimPrefix(c.header, "# ")))
+		if unescapeMarkdown(text) == c.title {
+			return text
+		}
+	}
+	return c.title
+}
+
+// isCardLink reports whether path points to a card file rather than to some
+// other document linked from kanban.md.
+func isCardLink(path string) bool {
+	return strings.HasPrefix(filepath.ToSlash(filepath.Clean(path)), DataDirName+"/")
+}
+
+func parseBoardFile(data []byte) (boardFile, error) {
+	var bf boardFile
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	// Why: A final newline would otherwise read as a trailing blank line.
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	i := 0
+	// Why: Front matter may hold YAML comments, which look like headers.
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i = 1; i < len(lines); i++ {
+			if t := strings.TrimSpace(lines[i]); t == "---" || t == "..." {
+				i++
+				break
+			}
+		}
+	}
+
+	body := lines[:i:i]
+	current := -1
+	flush := func() {
+		if current < 0 {
+			bf.preamble, bf.preambleGap = splitTrailingBlankLines(body)
+		} else {
+			bf.columns[current].setBody(body)
+		}
+		body = nil
+	}
+
+	inFence := false
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if !inFence && strings.HasPrefix(line, "# ") {
+			flush()
+			title, limit := parseColumnHeader(strings.TrimSpace(strings.TrimPrefix(line, "# ")))
+			bf.columns = append(bf.columns, boardFileColumn{title: title, limit: limit, header: line})
+			current = len(bf.columns) - 1
+			continue
+		}
+		body = append(body, line)
+	}
+	flush()
+
+	// Why: The blank lines closing the last part are those of the file, not
+	// a gap before whatever is added after it.
+	if n := len(bf.columns); n > 0 {
+		bf.end, bf.columns[n-1].gap = bf.columns[n-1].gap, nil
+	} else {
+		bf.end, bf.preambleGap = bf.preambleGap, nil
+	}
+	return bf, nil
+}
+
+// setBody splits the lines below a column header into the description, the
+// card entries and the notes. Lines between two entries belong to the one
+// above; after the last entry only indented lines do.
+func (c *boardFileColumn) setBody(body []string) {
+	body, c.gap = splitTrailingBlankLines(body)
+	c.lead, body = splitLeadingBlankLines(body)
+	var (
+		rest     []string
+		inFence  bool
+		lastLink = -1
+	)
+	for _, line := range body {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if title, path, ok := parseCardLink(line); !inFence && ok && isCardLink(path) {
+			if lastLink < 0 {
+				c.description, c.listGap = splitTrailingBlankLines(rest)
+			} else {
+				c.links[lastLink].annotation = rest
+			}
+			rest = nil
+			c.links = append(c.links, cardLink{title: title, path: path, line: line})
+			lastLink = len(c.links) - 1
+			continue
+		}
+		rest = append(rest, line)
+	}
+
+	if lastLink < 0 {
+		// Why: Without cards there is no list to tell the two apart, so the
+		// first paragraph is taken as the description.
+		n := paragraphEnd(rest)
+		c.description = rest[:n]
+		c.notesGap, c.notes = splitLeadingBlankLines(rest[n:])
+		return
+	}
+	n := 0
+	for n < len(rest) && strings.TrimSpace(rest[n]) != "" && (rest[n][0] == ' ' || rest[n][0] == '\t') {
+		n++
+	}
+	c.links[lastLink].annotation = rest[:n]
+	c.notesGap, c.notes = splitLeadingBlankLines(rest[n:])
+}
+
+// paragraphEnd returns the index of the first blank line outside a code
+// fence, or len(lines).
+func paragraphEnd(lines []string) int {
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if !inFence && strings.TrimSpace(line) == "" {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+// splitLeadingBlankLines splits lines after its leading blank lines. The
+// blank lines are never nil, as the file is known to have them.
+func splitLeadingBlankLines(lines []string) (blank, rest []string) {
+	n := 0
+	for n < len(lines) && strings.TrimSpace(lines[n]) == "" {
+		n++
+	}
+	return append([]string{}, lines[:n]...), lines[n:]
+}
+
+// splitTrailingBlankLines splits lines before its trailing blank lines. The
+// blank lines are never nil, as the file is known to have them.
+func splitTrailingBlankLines(lines []string) (rest, blank []string) {
+	n := len(lines)
+	for n > 0 && strings.TrimSpace(lines[n-1]) == "" {
+		n--
+	}
+	return lines[:n], append([]string{}, lines[n:]...)
+}
+
+// boardFileOf lays out b the way WriteBoard stores it. The Archived column is
+// only written while it holds cards or text.
+func boardFileOf(b board.Board) boardFile {
+	cols := make([]column.Column, len(b.Columns))
+	copy(cols, b.Columns)
+	if len(b.Archived.Cards) > 0 || len(b.Archived.Description) > 0 || len(b.Archived.Notes) > 0 {
+		cols = append(cols, b.Archived)
+	}
+
+	bf := boardFile{preamble: b.Preamble}
+	for _, col := range cols {
+		fc := boardFileColumn{
+			title:       col.Title,
+			limit:       col.Limit,
+			description: col.Description,
+			notes:       col.Notes,
+		}
+		for _, c := range col.Cards {
+			fc.links = append(fc.links, cardLink{title: c.Title, path: c.Path, annotation: c.Annotation})
+		}
+		bf.columns = append(bf.columns, fc)
+	}
+	return bf
+}
+
+// headerLine returns the header as read, or formatted if the title or the WIP
+// limit changed since.
+func (c boardFileColumn) headerLine() string {
+	if strings.HasPrefix(c.header, "# ") {
+		title, limit := parseColumnHeader(strings.TrimSpace(strings.TrimPrefix(c.header, "# ")))
+		if title == c.title && limit == c.limit {
+			return c.header + "\n"
+		}
+	}
+	return formatColumnHeader(column.Column{Title: c.title, Limit: c.limit})
+}
+
+// entryLine returns the entry as read, or formatted if the card's title or
+// path changed since.
+func (l cardLink) entryLine() string {
+	if title, path, ok := parseCardLink(l.line); ok && title == l.title && filepath.Clean(path) == filepath.Clean(l.path) {
+		return l.line
+	}
+	return formatCardLink(l.title, l.path)
+}
+
+// keepLayout takes the header lines, card entry lines and blank lines of old,
+// the file on disk, wherever bf still has the same columns and cards. Columns
+// are matched by title, or by position if they were renamed.
+//
+// Entries of cards whose file cannot be read are not on the board, and are
+// kept as they were so that doctor can still relink them.
+func (bf *boardFile) keepLayout(old boardFile) {
+	bf.preambleGap, bf.end = old.preambleGap, old.end
+
+	titles := make(map[string]bool, len(bf.columns))
+	listed := make(map[string]bool)
+	for _, col := range bf.columns {
+		titles[col.title] = true
+		for _, link := range col.links {
+			listed[link.uuid()] = true
+		}
+	}
+	oldByTitle := make(map[string]boardFileColumn, len(old.columns))
+	for _, col := range old.columns {
+		oldByTitle[col.title] = col
+	}
+
+	for i := range bf.columns {
+		col := &bf.columns[i]
+		prev, ok := oldByTitle[col.title]
+		if !ok && i < len(old.columns) && !titles[old.columns[i].title] {
+			prev, ok = old.columns[i], true
+		}
+		if !ok {
+			continue
+		}
+		col.header = prev.header
+		col.lead, col.listGap, col.notesGap, col.gap = prev.lead, prev.listGap, prev.notesGap, prev.gap
+
+		lines := make(map[string]string, len(prev.links))
+		for _, link := range prev.links {
+			lines[filepath.Clean(link.path)] = link.line
+		}
+		for j := range col.links {
+			col.links[j].line = lines[filepath.Clean(col.links[j].path)]
+		}
+		col.links = keepUnreadable(prev.links, col.links, listed)
+	}
+}
+
+// keepUnreadable puts the entries of old whose card is neither in listed nor
+// readable back into links, after the entry that preceded them in old.
+func keepUnreadable(old, links []cardLink, listed map[string]bool) []cardLink {
+	pos := 0
+	for _, link := range old {
+		if i := slices.IndexFunc(links, func(l cardLink) bool { return l.uuid() == link.uuid() }); i >= 0 {
+			pos = i + 1
+			continue
+		}
+		if listed[link.uuid()] {
+			continue
+		}
+		if _, err := LoadCard(link.path); err == nil {
+			continue
+		}
+		links = slices.Insert(links, pos, link)
+		pos++
+	}
+	return links
+}
+
+// format renders the file. Header and card entry lines are written as read
+// unless the column or card they name changed, and blank lines as read, or
+// as one blank line between parts the file did not have.
+func (bf boardFile) format() []byte {
+	var builder strings.Builder
+	writeLines := func(lines []string) {
+		for _, line := range lines {
+			builder.WriteString(line)
+			builder.WriteString("\n")
+		}
+	}
+	writeGap := func(blank []string) {
+		if blank == nil {
+			builder.WriteString("\n")
+		}
+		writeLines(blank)
+	}
+
+	writeLines(bf.preamble)
+	if len(bf.columns) > 0 && (len(bf.preamble) > 0 || bf.preambleGap != nil) {
+		writeGap(bf.preambleGap)
+	}
+	for i, col := range bf.columns {
+		builder.WriteString(col.headerLine())
+		writeLines(col.lead)
+		writeLines(col.description)
+		if len(col.links) > 0 {
+			if len(col.description) > 0 {
+				writeGap(col.listGap)
+			}
+			for _, link := range col.links {
+				builder.WriteString(link.entryLine() + "\n")
+				writeLines(link.annotation)
+			}
+		}
+		if len(col.notes) > 0 {
+			if len(col.description) > 0 || len(col.links) > 0 {
+				writeGap(col.notesGap)
+			}
+			writeLines(col.notes)
+		}
+		if i < len(bf.columns)-1 {
+			writeGap(col.gap)
+		}
+	}
+	writeLines(bf.end)
+	return []byte(builder.String())
+}
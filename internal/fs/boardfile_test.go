@@ -0,0 +1,225 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"kanban/internal/card"
+)
+
+func TestBoardFileRoundTrip(t *testing.T) {
+	tests := []struct {
+		name, data string
+	}{
+		{"plain", "# Todo\n- [a](.kanban/Todo/1.md)\n\n# Done\n"},
+		{"empty columns", "# Todo\n\n# Done\n"},
+		{"no final newline", "# Todo\n- [a](.kanban/Todo/1.md)"},
+		{"trailing blank lines", "# Todo\n- [a](.kanban/Todo/1.md)\n\n\n"},
+		{"front matter", "---\ntitle: Board\n# not a column\n---\n\nIntro\n\n# Todo\n"},
+		{"glued headers", "# Todo\n# Done\n"},
+		{"glued list", "# Todo\nDescription\n- [a](.kanban/Todo/1.md)\nNotes\n# Done\n"},
+		{"spacing", "\n\nIntro\n\n\n# Todo\n\n\nDescription\n\n\n- [a](.kanban/Todo/1.md)\n  sub-item\n\n- [b](.kanban/Todo/2.md)\n\n\nNotes\n\n\n# Done\n\n"},
+		{"cardless text", "# Todo\n\nDescription\n\nMore notes\n\n# Done\n"},
+		{"list styles", "# Todo\n* [a](.kanban/Todo/1.md)\n+   [b](<.kanban/Todo/2.md>)\n"},
+		{"unescaped entry", "# In Progress\n- [a [b] c](.kanban/In Progress/1.md)\n"},
+		{"wip limit", "# Doing   <!--wip:3-->\n"},
+		{"fenced header", "# Todo\n```\n# not a column\n- [a](.kanban/Todo/1.md)\n```\n"},
+		{"other links", "# Todo\n- [docs](https://example.com)\n- [a](.kanban/Todo/1.md)\n"},
+		{"whitespace-only blank lines", "# Todo\n  \n- [a](.kanban/Todo/1.md)\n\t\n# Done\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bf, err := parseBoardFile([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := tt.data
+			if !strings.HasSuffix(want, "\n") {
+				want += "\n"
+			}
+			if got := string(bf.format()); got != want {
+				t.Errorf("format() = %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestParseBoardFile(t *testing.T) {
+	data := "Intro\n\n# Todo <!-- wip: 2 -->\nDescription\n\n- [a](.kanban/Todo/1.md)\n  sub-item\n\n- [b \\[x\\]](<.kanban/Todo/2 b.md>)\n\nNotes\n\n# Done\n\nFirst paragraph\n\nSecond paragraph\n"
+	bf, err := parseBoardFile([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"Intro"}; !reflect.DeepEqual(bf.preamble, want) {
+		t.Errorf("preamble = %q; want %q", bf.preamble, want)
+	}
+	if len(bf.columns) != 2 {
+		t.Fatalf("got %d columns; want 2", len(bf.columns))
+	}
+
+	todo := bf.columns[0]
+	if todo.title != "Todo" || todo.limit != 2 {
+		t.Errorf("header read as %q, %d; want Todo, 2", todo.title, todo.limit)
+	}
+	if want := []string{"Description"}; !reflect.DeepEqual(todo.description, want) {
+		t.Errorf("description = %q; want %q", todo.description, want)
+	}
+	if want := []string{"Notes"}; !reflect.DeepEqual(todo.notes, want) {
+		t.Errorf("notes = %q; want %q", todo.notes, want)
+	}
+	wantLinks := []struct {
+		title, path string
+		annotation  []string
+	}{
+		{"a", ".kanban/Todo/1.md", []string{"  sub-item", ""}},
+		{"b [x]", ".kanban/Todo/2 b.md", []string{}},
+	}
+	if len(todo.links) != len(wantLinks) {
+		t.Fatalf("got %d card entries; want %d", len(todo.links), len(wantLinks))
+	}
+	for i, want := range wantLinks {
+		link := todo.links[i]
+		if link.title != want.title || link.path != want.path || !reflect.DeepEqual(link.annotation, want.annotation) {
+			t.Errorf("entry %d = %q, %q, %q; want %q, %q, %q", i, link.title, link.path, link.annotation, want.title, want.path, want.annotation)
+		}
+	}
+
+	// Why: Without cards the first paragraph is the description.
+	done := bf.columns[1]
+	if want := []string{"First paragraph"}; !reflect.DeepEqual(done.description, want) {
+		t.Errorf("description = %q; want %q", done.description, want)
+	}
+	if want := []string{"Second paragraph"}; !reflect.DeepEqual(done.notes, want) {
+		t.Errorf("notes = %q; want %q", done.notes, want)
+	}
+}
+
+func TestFormatRewritesChangedEntries(t *testing.T) {
+	data := "Intro\n\n\n# Todo\n\n* [a](.kanban/Todo/1.md)\n*   [b](.kanban/Todo/2.md)\n\n\n# Done\nFinished cards\n\n\nOld ones are archived\n"
+	old, err := parseBoardFile([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Why: Build the new layout the way OverwriteBoard does, from the board
+	// rather than from the file: rename b and move a to Done.
+	bf := old
+	bf.columns = []boardFileColumn{
+		{title: "Todo", links: []cardLink{{title: "b [new]", path: ".kanban/Todo/2.md"}}},
+		{title: "Done", description: []string{"Finished cards"}, notes: []string{"Old ones are archived"}, links: []cardLink{{title: "a", path: ".kanban/Done/1.md"}}},
+		{title: "Later", links: []cardLink{{title: "c", path: ".kanban/Later/3.md"}}},
+	}
+	bf.keepLayout(old)
+
+	want := "Intro\n\n\n# Todo\n\n- [b \\[new\\]](.kanban/Todo/2.md)\n\n\n# Done\nFinished cards\n\n- [a](.kanban/Done/1.md)\n\n\nOld ones are archived\n\n# Later\n- [c](.kanban/Later/3.md)\n"
+	if got := string(bf.format()); got != want {
+		t.Errorf("format() = %q; want %q", got, want)
+	}
+}
+
+func TestKeepLayoutOfRenamedColumn(t *testing.T) {
+	old, err := parseBoardFile([]byte("# Todo\n\n\n* [a](.kanban/Todo/1.md)\n\n\n# Done\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bf := boardFile{columns: []boardFileColumn{
+		{title: "Backlog", links: []cardLink{{title: "a", path: ".kanban/Backlog/1.md"}}},
+		{title: "Done"},
+	}}
+	bf.keepLayout(old)
+
+	want := "# Backlog\n\n\n- [a](.kanban/Backlog/1.md)\n\n\n# Done\n"
+	if got := string(bf.format()); got != want {
+		t.Errorf("format() = %q; want %q", got, want)
+	}
+}
+
+func TestWriteBoardKeepsFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, dir := range []string{"Todo", "Q3: Plan", "Done"} {
+		if err := os.MkdirAll(filepath.Join(DataDirName, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := card.New("first [draft]")
+	c.UUID = "11111111-1111-1111-1111-111111111111"
+	c.Path = filepath.Join(DataDirName, "Q3: Plan", c.UUID+".md")
+	if err := WriteCard(c); err != nil {
+		t.Fatal(err)
+	}
+
+	data := "---\nowner: me\n---\n\n# Todo\nSomething to do.\n\n\n# Q3: Plan <!-- wip: 4 -->\n\n*  [first \\[draft\\]](<.kanban/Q3: Plan/" + c.UUID + ".md>)\n   - sub-item\n\n# Done\n"
+	if err := os.WriteFile(BoardFileName, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := LoadBoard()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := b.FindColumn("Q3: Plan")
+	if plan == nil || plan.Limit != 4 || len(plan.Cards) != 1 || plan.Cards[0].Title != "first [draft]" {
+		t.Fatalf("loaded column = %+v", plan)
+	}
+	if want := filepath.Join(DataDirName, "Q3: Plan"); plan.Path != want {
+		t.Errorf("column path = %q; want the legacy directory %q", plan.Path, want)
+	}
+
+	if err := WriteBoard(b); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(BoardFileName); string(got) != data {
+		t.Errorf("unchanged board written as %q; want %q", got, data)
+	}
+}
+
+func TestWriteBoardKeepsUnreadableEntries(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, dir := range []string{"Todo", "Done"} {
+		if err := os.MkdirAll(filepath.Join(DataDirName, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, title := range []string{"a", "b"} {
+		c := card.New(title)
+		c.UUID = title
+		c.Path = filepath.Join(DataDirName, "Todo", title+".md")
+		if err := WriteCard(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(DataDirName, "Todo", "invalid.md"), []byte("---\ntitle: [\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	data := "# Todo\n- [missing](.kanban/Todo/missing.md)\n  note\n- [a](.kanban/Todo/a.md)\n*  [invalid](.kanban/Todo/invalid.md)\n- [b](.kanban/Todo/b.md)\n\n# Done\n"
+	if err := os.WriteFile(BoardFileName, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b, err := LoadBoard()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(b.Columns[0].Cards); got != 2 {
+		t.Fatalf("loaded %d cards; want 2", got)
+	}
+
+	// Why: Move a to Done the way the TUI does, without touching its file.
+	todo, done := &b.Columns[0], &b.Columns[1]
+	a := todo.Cards[0]
+	a.Path = filepath.Join(DataDirName, "Done", "a.md")
+	todo.Cards = todo.Cards[1:]
+	done.Cards = append(done.Cards, a)
+	if err := WriteBoard(b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# Todo\n- [missing](.kanban/Todo/missing.md)\n  note\n*  [invalid](.kanban/Todo/invalid.md)\n- [b](.kanban/Todo/b.md)\n\n# Done\n- [a](.kanban/Done/a.md)\n"
+	if got, _ := os.ReadFile(BoardFileName); string(got) != want {
+		t.Errorf("board written as %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,138 @@
+// internal/fs/config.go
+package fs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const ConfigFileName = "config.yaml"
+
+// Config holds the user's settings: ~/.config/kanban/config.yaml, overridden
+// by .kanban/config.yaml of the board.
+type Config struct {
+	// Editor opens cards, before $EDITOR.
+	Editor string `yaml:"editor"`
+	// DoneColumn is the done column of boards that have none set.
+	DoneColumn string `yaml:"done_column"`
+	// CreateCardMode is where :new puts cards: prepend or append.
+	CreateCardMode string `yaml:"create_card_mode"`
+	// Theme names the theme in Themes, or a built-in one, to use.
+	Theme string `yaml:"theme"`
+	// Themes map a theme name to the colors of the styles it changes.
+	Themes map[string]map[string]StyleColors `yaml:"themes"`
+	// Keys map a mode (normal or visual) to the keys of the actions it rebinds.
+	Keys map[string]map[string]KeyList `yaml:"keys"`
+}
+
+// StyleColors are the colors a theme gives one style. Empty colors are left
+// as they are.
+type StyleColors struct {
+	Foreground string `yaml:"fg"`
+	Background string `yaml:"bg"`
+	Border     string `yaml:"border"`
+}
+
+// KeyList is the keys bound to an action. Two keys separated by a space, such
+// as "g g", form a sequence. The config file may give one key as a string.
+type KeyList []string
+
+func (k *KeyList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*k = KeyList{value.Value}
+		return nil
+	}
+	var keys []string
+	if err := value.Decode(&keys); err != nil {
+		return err
+	}
+	*k = keys
+	return nil
+}
+
+// GlobalConfigPath returns the path of the user's config file, honoring
+// $XDG_CONFIG_HOME.
+func GlobalConfigPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "kanban", ConfigFileName), nil
+}
+
+// LoadConfig reads the user's config file and merges the config of the board
+// in the current directory over it. Missing files are not an error.
+func LoadConfig() (Config, error) {
+	var cfg Config
+	if path, err := GlobalConfigPath(); err == nil {
+		if err := readConfig(path, &cfg); err != nil {
+			return Config{}, err
+		}
+	}
+	var board Config
+	if err := readConfig(filepath.Join(DataDirName, ConfigFileName), &board); err != nil {
+		return Config{}, err
+	}
+	cfg.merge(board)
+	return cfg, nil
+}
+
+func readConfig(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// merge overrides c with the settings over sets. Themes are merged style by
+// style and key bindings action by action.
+func (c *Config) merge(over Config) {
+	if over.Editor != "" {
+		c.Editor = over.Editor
+	}
+	if over.DoneColumn != "" {
+		c.DoneColumn = over.DoneColumn
+	}
+	if over.CreateCardMode != "" {
+		c.CreateCardMode = over.CreateCardMode
+	}
+	if over.Theme != "" {
+		c.Theme = over.Theme
+	}
+	for name, styles := range over.Themes {
+		if c.Themes == nil {
+			c.Themes = make(map[string]map[string]StyleColors)
+		}
+		if c.Themes[name] == nil {
+			c.Themes[name] = make(map[string]StyleColors)
+		}
+		for style, colors := range styles {
+			c.Themes[name][style] = colors
+		}
+	}
+	for mode, actions := range over.Keys {
+		if c.Keys == nil {
+			c.Keys = make(map[string]map[string]KeyList)
+		}
+		if c.Keys[mode] == nil {
+			c.Keys[mode] = make(map[string]KeyList)
+		}
+		for action, keys := range actions {
+			c.Keys[mode][action] = keys
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigMerge(t *testing.T) {
+	tests := []struct {
+		name          string
+		global, board Config
+		want          Config
+	}{
+		{
+			name:   "empty board config",
+			global: Config{Editor: "vim", DoneColumn: "Done", Theme: "dark"},
+			want:   Config{Editor: "vim", DoneColumn: "Done", Theme: "dark"},
+		},
+		{
+			name:   "scalars",
+			global: Config{Editor: "vim", DoneColumn: "Done", CreateCardMode: "append"},
+			board:  Config{DoneColumn: "Shipped", Theme: "light"},
+			want:   Config{Editor: "vim", DoneColumn: "Shipped", CreateCardMode: "append", Theme: "light"},
+		},
+		{
+			name: "themes by style",
+			global: Config{Themes: map[string]map[string]StyleColors{
+				"mine":  {"card": {Foreground: "1"}, "column": {Border: "2"}},
+				"other": {"card": {Foreground: "3"}},
+			}},
+			board: Config{Themes: map[string]map[string]StyleColors{
+				"mine": {"card": {Background: "4"}},
+				"new":  {"title": {Foreground: "5"}},
+			}},
+			want: Config{Themes: map[string]map[string]StyleColors{
+				"mine":  {"card": {Background: "4"}, "column": {Border: "2"}},
+				"other": {"card": {Foreground: "3"}},
+				"new":   {"title": {Foreground: "5"}},
+			}},
+		},
+		{
+			name:  "themes without global ones",
+			board: Config{Themes: map[string]map[string]StyleColors{"mine": {"card": {Foreground: "1"}}}},
+			want:  Config{Themes: map[string]map[string]StyleColors{"mine": {"card": {Foreground: "1"}}}},
+		},
+		{
+			name: "keys by action",
+			global: Config{Keys: map[string]map[string]KeyList{
+				"normal": {"down": {"j", "n"}, "up": {"k"}},
+			}},
+			board: Config{Keys: map[string]map[string]KeyList{
+				"normal": {"down": {"J"}},
+				"visual": {"exit": {"q"}},
+			}},
+			want: Config{Keys: map[string]map[string]KeyList{
+				"normal": {"down": {"J"}, "up": {"k"}},
+				"visual": {"exit": {"q"}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.global
+			got.merge(tt.board)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("merge() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Chdir(t.TempDir())
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	global := "editor: vim\ndone_column: Done\nkeys:\n  normal:\n    down: n\n    up: [k, e]\n"
+	board := "done_column: Shipped\nkeys:\n  normal:\n    down: [J]\n"
+	if err := os.MkdirAll(filepath.Join(xdg, "kanban"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(xdg, "kanban", ConfigFileName), []byte(global), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(DataDirName, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(DataDirName, ConfigFileName), []byte(board), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{
+		Editor:     "vim",
+		DoneColumn: "Shipped",
+		Keys:       map[string]map[string]KeyList{"normal": {"down": {"J"}, "up": {"k", "e"}}},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("LoadConfig() = %+v; want %+v", cfg, want)
+	}
+}
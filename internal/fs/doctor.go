@@ -0,0 +1,428 @@
+// internal/fs/doctor.go
+package fs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"kanban/internal/card"
+)
+
+// ProblemKind classifies an inconsistency found by CheckBoard.
+type ProblemKind string
+
+const (
+	ProblemMissingFile      ProblemKind = "missing"
+	ProblemInvalidCard      ProblemKind = "invalid"
+	ProblemMisplaced        ProblemKind = "misplaced"
+	ProblemDuplicate        ProblemKind = "duplicate"
+	ProblemOrphan           ProblemKind = "orphan"
+	ProblemUnknownColumn    ProblemKind = "unknown column"
+	ProblemMissingColumnDir ProblemKind = "missing column"
+)
+
+// Problem is one inconsistency between kanban.md and the files in the data
+// directory.
+type Problem struct {
+	Kind ProblemKind
+	// Path is the card file or column directory concerned.
+	Path   string
+	Detail string
+	// Fixable problems can be repaired by FixBoard.
+	Fixable bool
+
+	uuid   string
+	target string // Where a misplaced card file belongs
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Kind, p.Detail)
+}
+
+// CheckBoard compares kanban.md in the current directory with the card files
+// and column directories under the data directory.
+func CheckBoard() ([]Problem, error) {
+	data, err := os.ReadFile(BoardFileName)
+	if err != nil {
+		return nil, err
+	}
+	bf, err := parseBoardFile(data)
+	if err != nil {
+		return nil, err
+	}
+	onDisk, err := scanCardFiles()
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := columnDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+
+	known := make(map[string]bool)
+	for _, fc := range bf.columns {
+		known[filepath.Clean(fc.dir())] = true
+		if _, err := os.Stat(fc.dir()); os.IsNotExist(err) {
+			problems = append(problems, Problem{
+				Kind:    ProblemMissingColumnDir,
+				Path:    fc.dir(),
+				Detail:  fmt.Sprintf("column '%s' has no directory %s", fc.title, fc.dir()),
+				Fixable: true,
+			})
+		}
+	}
+	// Why: The cards in the old directory of a column are moved along with
+	// it, not reported one by one.
+	legacy := make(map[string]bool)
+	for _, dir := range dirs {
+		if known[filepath.Clean(dir)] || filepath.Clean(dir) == ColumnDir(ArchiveColumnName) {
+			continue
+		}
+		p := Problem{Kind: ProblemUnknownColumn, Path: dir, Fixable: true}
+		if entries, _ := os.ReadDir(dir); len(entries) == 0 {
+			p.Detail = fmt.Sprintf("%s has no column header in %s (fix removes the empty directory)", dir, BoardFileName)
+		} else if col := bf.columnForLegacyDir(dir); col != nil {
+			p.Detail = fmt.Sprintf("%s is the old directory of column '%s', which is stored in %s (fix moves its cards there)", dir, col.title, col.dir())
+			legacy[filepath.Clean(dir)] = true
+		} else {
+			p.Detail = fmt.Sprintf("%s has no column header in %s (fix adds the column)", dir, BoardFileName)
+		}
+		problems = append(problems, p)
+	}
+
+	listed := make(map[string]bool)
+	for _, fc := range bf.columns {
+		for _, link := range fc.links {
+			uuid := link.uuid()
+			if listed[uuid] {
+				problems = append(problems, Problem{
+					Kind:    ProblemDuplicate,
+					Path:    link.path,
+					Detail:  fmt.Sprintf("'%s' is listed more than once (fix keeps the first entry)", link.title),
+					Fixable: true,
+					uuid:    uuid,
+				})
+				continue
+			}
+			listed[uuid] = true
+
+			want := filepath.Join(fc.dir(), uuid+".md")
+			found := link.path
+			if _, err := os.Stat(link.path); err != nil {
+				paths := onDisk[uuid]
+				if len(paths) == 0 {
+					problems = append(problems, Problem{
+						Kind:    ProblemMissingFile,
+						Path:    link.path,
+						Detail:  fmt.Sprintf("'%s' in column '%s' has no card file %s (fix removes the entry)", link.title, fc.title, link.path),
+						Fixable: true,
+						uuid:    uuid,
+					})
+					continue
+				}
+				found = paths[0]
+			}
+
+			if _, err := LoadCard(found); err != nil {
+				problems = append(problems, Problem{
+					Kind:   ProblemInvalidCard,
+					Path:   found,
+					Detail: fmt.Sprintf("'%s' (%s) cannot be read: %v", link.title, found, err),
+				})
+			}
+
+			if legacy[filepath.Dir(filepath.Clean(found))] {
+				continue
+			}
+			if filepath.Clean(found) != filepath.Clean(want) {
+				_, targetErr := os.Stat(want)
+				problems = append(problems, Problem{
+					Kind:    ProblemMisplaced,
+					Path:    found,
+					Detail:  fmt.Sprintf("'%s' in column '%s' is stored at %s instead of %s", link.title, fc.title, found, want),
+					Fixable: os.IsNotExist(targetErr),
+					uuid:    uuid,
+					target:  want,
+				})
+			} else if filepath.Clean(link.path) != filepath.Clean(want) {
+				problems = append(problems, Problem{
+					Kind:    ProblemMisplaced,
+					Path:    found,
+					Detail:  fmt.Sprintf("'%s' in column '%s' links to %s instead of %s", link.title, fc.title, link.path, want),
+					Fixable: true,
+					uuid:    uuid,
+					target:  want,
+				})
+			}
+		}
+	}
+
+	uuids := make([]string, 0, len(onDisk))
+	for uuid := range onDisk {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+	for _, uuid := range uuids {
+		paths := onDisk[uuid]
+		if len(paths) > 1 {
+			problems = append(problems, Problem{
+				Kind:   ProblemDuplicate,
+				Path:   paths[1],
+				Detail: fmt.Sprintf("card %s has several files: %s", uuid, strings.Join(paths, ", ")),
+			})
+		}
+		if listed[uuid] {
+			continue
+		}
+		for _, path := range paths {
+			p := Problem{Kind: ProblemOrphan, Path: path, uuid: uuid}
+			if c, err := LoadCard(path); err != nil {
+				p.Detail = fmt.Sprintf("%s is not listed in %s and cannot be read: %v", path, BoardFileName, err)
+			} else if legacy[filepath.Dir(filepath.Clean(path))] && len(paths) == 1 {
+				continue
+			} else {
+				p.Detail = fmt.Sprintf("'%s' (%s) is not listed in %s (fix adds it to its column)", c.Title, path, BoardFileName)
+				p.Fixable = len(paths) == 1
+			}
+			problems = append(problems, p)
+		}
+	}
+
+	return problems, nil
+}
+
+// FixBoard repairs the fixable problems, which must come from CheckBoard, and
+// rewrites kanban.md. It returns how many problems were fixed.
+func FixBoard(problems []Problem) (int, error) {
+	data, err := os.ReadFile(BoardFileName)
+	if err != nil {
+		return 0, err
+	}
+	bf, err := parseBoardFile(data)
+	if err != nil {
+		return 0, err
+	}
+
+	// Why: kanban.md is written even if a fix fails, so that it points to
+	// the files already moved.
+	fixed, changed := 0, false
+	for _, p := range problems {
+		if !p.Fixable {
+			continue
+		}
+		switch p.Kind {
+		case ProblemMissingColumnDir:
+			err = os.MkdirAll(p.Path, 0755)
+		case ProblemUnknownColumn:
+			if entries, _ := os.ReadDir(p.Path); len(entries) == 0 {
+				err = os.Remove(p.Path)
+			} else if col := bf.columnForLegacyDir(p.Path); col != nil {
+				err = bf.moveLegacyDir(p.Path, col)
+				changed = true
+			} else {
+				bf.columnForDir(p.Path)
+				changed = true
+			}
+		case ProblemMissingFile:
+			bf.removeLinks(p.uuid, false)
+			changed = true
+		case ProblemDuplicate:
+			bf.removeLinks(p.uuid, true)
+			changed = true
+		case ProblemMisplaced:
+			if filepath.Clean(p.Path) != filepath.Clean(p.target) {
+				err = os.Rename(p.Path, p.target)
+			}
+			if err == nil {
+				bf.setLinkPath(p.uuid, p.target)
+				changed = true
+			}
+		case ProblemOrphan:
+			var c card.Card
+			if c, err = LoadCard(p.Path); err == nil {
+				col := bf.columnForDir(filepath.Dir(p.Path))
+				col.links = append(col.links, cardLink{title: c.Title, path: p.Path})
+				changed = true
+			}
+		}
+		if err != nil {
+			err = fmt.Errorf("could not fix %s: %w", p.Path, err)
+			break
+		}
+		fixed++
+	}
+
+	if changed {
+		if writeErr := writeBoardFile(bf.format()); err == nil {
+			err = writeErr
+		}
+	}
+	return fixed, err
+}
+
+// columnForDir returns the column stored in dir, adding it (before the
+// Archived column) if kanban.md has no header for it. A directory named the
+// way older versions did belongs to the column it was named after.
+func (bf *boardFile) columnForDir(dir string) *boardFileColumn {
+	for i := range bf.columns {
+		if filepath.Clean(bf.columns[i].dir()) == filepath.Clean(dir) {
+			return &bf.columns[i]
+		}
+	}
+	if col := bf.columnForLegacyDir(dir); col != nil {
+		return col
+	}
+
+	col := boardFileColumn{title: columnTitle(filepath.Base(dir))}
+	at := len(bf.columns)
+	if at > 0 && bf.columns[at-1].title == ArchiveColumnName && col.title != ArchiveColumnName {
+		at--
+	}
+	bf.columns = append(bf.columns[:at], append([]boardFileColumn{col}, bf.columns[at:]...)...)
+	return &bf.columns[at]
+}
+
+// columnForLegacyDir returns the column whose title names dir the way older
+// versions did, or nil.
+func (bf *boardFile) columnForLegacyDir(dir string) *boardFileColumn {
+	for i := range bf.columns {
+		if legacy, ok := legacyColumnDir(bf.columns[i].legacyName()); ok && legacy == filepath.Clean(dir) {
+			return &bf.columns[i]
+		}
+	}
+	return nil
+}
+
+// moveLegacyDir moves the card files in dir, the old directory of col, into
+// the column's directory, listing those kanban.md did not, and removes dir
+// once it is empty.
+func (bf *boardFile) moveLegacyDir(dir string, col *boardFileColumn) error {
+	paths, err := cardFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		target := filepath.Join(col.dir(), filepath.Base(path))
+		if _, err := os.Stat(target); err == nil {
+			continue // Left for a later check to report as a duplicate
+		}
+		c, err := LoadCard(path)
+		if err != nil {
+			continue // Left for a later check to report as invalid
+		}
+		if err := os.Rename(path, target); err != nil {
+			return err
+		}
+		uuid := strings.TrimSuffix(filepath.Base(path), ".md")
+		if bf.hasLink(uuid) {
+			bf.setLinkPath(uuid, target)
+		} else {
+			col.links = append(col.links, cardLink{title: c.Title, path: target})
+		}
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) == 0 {
+		return os.Remove(dir)
+	}
+	return nil
+}
+
+func (bf *boardFile) hasLink(uuid string) bool {
+	for _, col := range bf.columns {
+		for _, link := range col.links {
+			if link.uuid() == uuid {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// removeLinks drops the entries of the card uuid, or all but the first one.
+func (bf *boardFile) removeLinks(uuid string, keepFirst bool) {
+	kept := !keepFirst
+	for i := range bf.columns {
+		links := bf.columns[i].links[:0]
+		for _, link := range bf.columns[i].links {
+			if link.uuid() == uuid {
+				if !kept {
+					kept = true
+					links = append(links, link)
+				}
+				continue
+			}
+			links = append(links, link)
+		}
+		bf.columns[i].links = links
+	}
+}
+
+func (bf *boardFile) setLinkPath(uuid, path string) {
+	for i := range bf.columns {
+		for j, link := range bf.columns[i].links {
+			if link.uuid() == uuid {
+				bf.columns[i].links[j].path = path
+			}
+		}
+	}
+}
+
+// columnDirs lists the column directories under the data directory, skipping
+// hidden ones such as the trash and the backups.
+func columnDirs() ([]string, error) {
+	entries, err := os.ReadDir(DataDirName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			dirs = append(dirs, filepath.Join(DataDirName, e.Name()))
+		}
+	}
+	return dirs, nil
+}
+
+// cardFiles lists the card files in dir.
+func cardFiles(dir string) ([]string, error) {
+	// Why: Not filepath.Glob, which would read brackets in column
+	// directories such as "Sprint [3]" as a pattern.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".md") {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	return paths, nil
+}
+
+// scanCardFiles maps the UUID of every card file to all of its paths.
+func scanCardFiles() (map[string][]string, error) {
+	dirs, err := columnDirs()
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]string)
+	for _, dir := range dirs {
+		paths, err := cardFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			uuid := strings.TrimSuffix(filepath.Base(path), ".md")
+			files[uuid] = append(files[uuid], path)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,58 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kanban/internal/card"
+)
+
+func TestCheckBoardWithBracketedColumns(t *testing.T) {
+	t.Chdir(t.TempDir())
+	// Why: With the encoded directory of "Q3: [x]" present, its legacy
+	// directory is one FixBoard moves the cards out of.
+	for _, dir := range []string{"Sprint [3]", "Todo [wip", "Q3: [x]", "Q3%3A [x]"} {
+		if err := os.MkdirAll(filepath.Join(DataDirName, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	orphan := card.New("orphan")
+	orphan.UUID = "orphan"
+	orphan.Path = filepath.Join(DataDirName, "Sprint [3]", "orphan.md")
+	legacy := card.New("legacy")
+	legacy.UUID = "legacy"
+	legacy.Path = filepath.Join(DataDirName, "Q3: [x]", "legacy.md")
+	for _, c := range []card.Card{orphan, legacy} {
+		if err := WriteCard(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data := "# Sprint \\[3\\]\n\n# Todo \\[wip\n\n# Q3: [x]\n- [legacy](<.kanban/Q3: [x]/legacy.md>)\n"
+	if err := os.WriteFile(BoardFileName, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := CheckBoard()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := make(map[ProblemKind]string)
+	for _, p := range problems {
+		kinds[p.Kind] = p.Path
+	}
+	if got := kinds[ProblemOrphan]; got != orphan.Path {
+		t.Errorf("orphan reported at %q; want %q in %v", got, orphan.Path, problems)
+	}
+
+	if _, err := FixBoard(problems); err != nil {
+		t.Fatal(err)
+	}
+	moved := filepath.Join(DataDirName, "Q3%3A [x]", "legacy.md")
+	if _, err := os.Stat(moved); err != nil {
+		t.Errorf("legacy card not moved to %s: %v", moved, err)
+	}
+	if problems, err := CheckBoard(); err != nil || len(problems) > 0 {
+		t.Errorf("CheckBoard() after FixBoard = %v, %v; want no problems", problems, err)
+	}
+}
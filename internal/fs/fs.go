@@ -2,14 +2,17 @@
 package fs
 
 import (
-	"bufio"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,6 +26,7 @@ const (
 	BoardFileName  = "kanban.md"
 	DataDirName    = ".kanban"
 	StateFileName  = "state.json"
+	JournalFileName = "journal.json"
 	frontMatterSep = "---\n"
 	ArchiveColumnName = "Archived"
 )
@@ -32,9 +36,71 @@ type AppState struct {
 	FocusedCard   int `json:"focused_card"`
 	DoneColumn    string `json:"done_column,omitempty"`
 	ShowHidden    bool   `json:"show_hidden,omitempty"`
+	// InternalTrash deletes cards into .kanban/.trash even when trash-cli is installed.
+	InternalTrash bool   `json:"internal_trash,omitempty"`
+	// Lanes is the card field swimlanes group by, or "" without swimlanes.
+	Lanes         string `json:"lanes,omitempty"`
+	// Preview is where the preview pane is docked, or "" while it is closed.
+	Preview       string `json:"preview,omitempty"`
+	// AutoDone moves a card to the done column when its checklist is completed.
+	AutoDone      bool   `json:"auto_done,omitempty"`
+	// Marks map a mark letter to the place it was set.
+	Marks         map[string]Position `json:"marks,omitempty"`
+	// Jumps is the jumplist, oldest first.
+	Jumps         []Position `json:"jumps,omitempty"`
+	// Registers map a register name to the UUIDs of the cards yanked into it.
+	Registers     map[string][]string `json:"registers,omitempty"`
 }
 
-var cardLinkRegex = regexp.MustCompile(`\s*-\s*\[(.*?)\]\((.*?)\)`)
+// Position is a place on the board for marks and the jumplist: a card, or the
+// header of a column when Card is "".
+type Position struct {
+	Card   string `json:"card,omitempty"`
+	Column string `json:"column,omitempty"`
+}
+
+// ErrBoardChanged is returned by WriteBoard when kanban.md was modified by
+// someone else since this process last read or wrote it.
+var ErrBoardChanged = errors.New(BoardFileName + " has changed on disk since it was loaded")
+
+// boardDigests holds the hash of each kanban.md as this process last saw it,
+// keyed by absolute path.
+var (
+	boardDigestsMu sync.Mutex
+	boardDigests   = make(map[string][sha256.Size]byte)
+)
+
+// columnLimitRegex matches the WIP limit comment ending a column header, e.g.
+// "WIP <!-- wip: 3 -->". It is a comment because plain titles such as
+// "Sprint (2024)" often end in a number.
+var columnLimitRegex = regexp.MustCompile(`^(.*?)\s*<!--\s*wip:\s*(\d+)\s*-->$`)
+
+// parseColumnHeader splits a header title into the column title and its WIP limit.
+func parseColumnHeader(header string) (string, int) {
+	text, limit := splitColumnHeader(header)
+	return unescapeMarkdown(text), limit
+}
+
+// splitColumnHeader splits a header title into the title as written, still
+// escaped, and the WIP limit.
+func splitColumnHeader(header string) (string, int) {
+	matches := columnLimitRegex.FindStringSubmatch(header)
+	if matches == nil || matches[1] == "" || isEscaped(header, strings.LastIndex(header, "<!--")) {
+		return header, 0
+	}
+	limit, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return header, 0
+	}
+	return matches[1], limit
+}
+
+func formatColumnHeader(col column.Column) string {
+	if col.Limit > 0 {
+		return fmt.Sprintf("# %s <!-- wip: %d -->\n", escapeColumnTitle(col.Title), col.Limit)
+	}
+	return fmt.Sprintf("# %s\n", escapeColumnTitle(col.Title))
+}
 
 func LoadBoard() (board.Board, error) {
 	wd, err := os.Getwd()
@@ -43,49 +109,39 @@ func LoadBoard() (board.Board, error) {
 	}
 	b := board.New(wd, []column.Column{})
 
-	f, err := os.Open(BoardFileName)
+	data, err := os.ReadFile(BoardFileName)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return b, nil
 		}
 		return board.Board{}, err
 	}
-	defer f.Close()
+	rememberBoard(data)
 
-	allCols := make([]column.Column, 0)
-	var currentColumn *column.Column
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "# ") {
-			if currentColumn != nil {
-				allCols = append(allCols, *currentColumn)
-			}
-			title := strings.TrimSpace(strings.TrimPrefix(line, "# "))
-			currentColumn = &column.Column{
-				Title: title,
-				Path:  filepath.Join(DataDirName, title),
-				Cards: []card.Card{},
-			}
-		} else if currentColumn != nil && cardLinkRegex.MatchString(line) {
-			matches := cardLinkRegex.FindStringSubmatch(line)
-			if len(matches) == 3 {
-				cardPath := matches[2]
-				c, err := LoadCard(cardPath)
-				if err == nil {
-					currentColumn.Cards = append(currentColumn.Cards, c)
-				}
+	bf, err := parseBoardFile(data)
+	if err != nil {
+		return board.Board{}, err
+	}
+	b.Preamble = bf.preamble
+
+	allCols := make([]column.Column, 0, len(bf.columns))
+	for _, fc := range bf.columns {
+		col := column.Column{
+			Title:       fc.title,
+			Path:        fc.dir(),
+			Cards:       []card.Card{},
+			Limit:       fc.limit,
+			Description: fc.description,
+			Notes:       fc.notes,
+		}
+		for _, link := range fc.links {
+			c, err := LoadCard(link.path)
+			if err == nil {
+				c.Annotation = link.annotation
+				col.Cards = append(col.Cards, c)
 			}
 		}
-	}
-
-	if currentColumn != nil {
-		allCols = append(allCols, *currentColumn)
-	}
-
-	if err := scanner.Err(); err != nil {
-		return board.Board{}, err
+		allCols = append(allCols, col)
 	}
 
 	displayCols := make([]column.Column, 0)
@@ -103,32 +159,75 @@ func LoadBoard() (board.Board, error) {
 	if archivedCol != nil {
 		b.Archived = *archivedCol
 	} else {
-		b.Archived = column.New(ArchiveColumnName, filepath.Join(DataDirName, ArchiveColumnName))
+		b.Archived = column.New(ArchiveColumnName, ColumnDir(ArchiveColumnName))
 	}
 
 	return b, nil
 }
 
+// WriteBoard writes b to kanban.md unless the file was changed by someone
+// else since it was loaded, in which case ErrBoardChanged is returned.
 func WriteBoard(b board.Board) error {
-	var builder strings.Builder
-
-	allColumns := make([]column.Column, len(b.Columns))
-	copy(allColumns, b.Columns)
-	if len(b.Archived.Cards) > 0 {
-		allColumns = append(allColumns, b.Archived)
+	if BoardChangedOnDisk() {
+		return ErrBoardChanged
 	}
+	return OverwriteBoard(b)
+}
 
-	for i, col := range allColumns {
-		builder.WriteString(fmt.Sprintf("# %s\n", col.Title))
-		for _, crd := range col.Cards {
-			builder.WriteString(fmt.Sprintf("- [%s](%s)\n", crd.Title, crd.Path))
-		}
-		if i < len(allColumns)-1 {
-			builder.WriteString("\n")
+// OverwriteBoard writes b to kanban.md, discarding any changes made to the
+// file by others.
+func OverwriteBoard(b board.Board) error {
+	bf := boardFileOf(b)
+	if data, err := os.ReadFile(BoardFileName); err == nil {
+		if old, err := parseBoardFile(data); err == nil {
+			bf.keepLayout(old)
 		}
 	}
+	return writeBoardFile(bf.format())
+}
 
-	return os.WriteFile(BoardFileName, []byte(builder.String()), 0644)
+// writeBoardFile backs up kanban.md and atomically replaces it with data.
+func writeBoardFile(data []byte) error {
+	if err := backupBoard(); err != nil {
+		return fmt.Errorf("could not back up %s: %w", BoardFileName, err)
+	}
+	if err := WriteFileAtomic(BoardFileName, data, 0644); err != nil {
+		return err
+	}
+	rememberBoard(data)
+	return nil
+}
+
+func boardDigestKey() string {
+	path, err := filepath.Abs(BoardFileName)
+	if err != nil {
+		return BoardFileName
+	}
+	return path
+}
+
+func rememberBoard(data []byte) {
+	boardDigestsMu.Lock()
+	defer boardDigestsMu.Unlock()
+	boardDigests[boardDigestKey()] = sha256.Sum256(data)
+}
+
+// BoardChangedOnDisk reports whether kanban.md differs from what this process
+// last read or wrote. A board never seen before is not considered changed.
+func BoardChangedOnDisk() bool {
+	boardDigestsMu.Lock()
+	known, ok := boardDigests[boardDigestKey()]
+	boardDigestsMu.Unlock()
+	if !ok {
+		return false
+	}
+	data, err := os.ReadFile(BoardFileName)
+	if err != nil {
+		// Why: A deleted file has nothing left to clobber; other read errors
+		// will surface from the write itself.
+		return false
+	}
+	return sha256.Sum256(data) != known
 }
 
 func SetupMainBoard(kanbanFilePaths []string) error {
@@ -251,7 +350,7 @@ func CreateSampleBoard(b *board.Board) error {
 	}
 
 	for _, colName := range sampleCols {
-		colPath := filepath.Join(DataDirName, colName)
+		colPath := ColumnDir(colName)
 		if err := os.Mkdir(colPath, 0755); err != nil && !os.IsExist(err) {
 			return err
 		}
@@ -284,6 +383,7 @@ func LoadCard(path string) (card.Card, error) {
 	}
 
 	c.Content = strings.TrimSpace(parts[2])
+	c.Checklist = card.ParseChecklist(c.Content)
 	c.Path = path
 	c.UUID = strings.TrimSuffix(filepath.Base(path), ".md")
 	c.Size = fileInfo.Size()
@@ -319,7 +419,11 @@ func CreateCard(col column.Column, title string) (card.Card, error) {
 
 func WriteCard(c card.Card) error {
 	c.ModifiedAt = time.Now()
+	return writeCardFile(c)
+}
 
+// writeCardFile writes c as-is, keeping its ModifiedAt.
+func writeCardFile(c card.Card) error {
 	frontMatter, err := yaml.Marshal(&c)
 	if err != nil {
 		return err
@@ -327,7 +431,7 @@ func WriteCard(c card.Card) error {
 
 	content := fmt.Sprintf("%s%s%s\n%s\n", frontMatterSep, string(frontMatter), frontMatterSep, c.Content)
 
-	return os.WriteFile(c.Path, []byte(content), 0644)
+	return WriteFileAtomic(c.Path, []byte(content), 0644)
 }
 
 func MoveCard(c *card.Card, destCol column.Column) error {
@@ -339,10 +443,10 @@ func MoveCard(c *card.Card, destCol column.Column) error {
 
 	err := os.Rename(c.Path, newPath)
 	if err != nil {
-		// This handles state desynchronization after an undo that only reverts
-		// in-memory state but not filesystem operations. If the rename fails
-		// because the source file doesn't exist, we check if it's because the
-		// file is *already* at the destination.
+		// This handles the card having been moved behind our back, e.g. by
+		// another editor. If the rename fails because the source file doesn't
+		// exist, we check if it's because the file is *already* at the
+		// destination.
 		if os.IsNotExist(err) {
 			if _, statErr := os.Stat(newPath); statErr == nil {
 				c.Path = newPath
@@ -361,41 +465,51 @@ func CopyCard(c card.Card, destCol column.Column) (card.Card, error) {
 		return card.Card{}, err
 	}
 	newCard.Content = c.Content
+	newCard.Checklist = c.Checklist
 	newCard.Link = c.Link
+	newCard.Tags = append([]string(nil), c.Tags...)
+	newCard.Due = c.Due
+	newCard.Priority = c.Priority
+	newCard.Assignee = c.Assignee
+	newCard.Lane = c.Lane
 	if err := WriteCard(newCard); err != nil {
 		return card.Card{}, err
 	}
 	return newCard, nil
 }
 
-func TrashCard(c card.Card) error {
-	_, err := exec.LookPath("trash")
-	if err != nil {
-		return fmt.Errorf("'trash' command not found, please install trash-cli")
+// TrashCard deletes the card file through trash-cli, or into the internal
+// trash when internal is set or trash-cli is not installed.
+func TrashCard(c card.Card, internal bool) error {
+	if !internal && hasTrashCLI() {
+		return exec.Command("trash", c.Path).Run()
 	}
-	cmd := exec.Command("trash", c.Path)
-	return cmd.Run()
+	return moveToInternalTrash(c)
+}
+
+func hasTrashCLI() bool {
+	_, err := exec.LookPath("trash")
+	return err == nil
 }
 
 func CreateColumn(name string) (column.Column, error) {
-	colPath := filepath.Join(DataDirName, name)
+	colPath := ColumnDir(name)
 	if err := os.Mkdir(colPath, 0755); err != nil {
 		return column.Column{}, err
 	}
 	return column.New(name, colPath), nil
 }
 
-func DeleteColumn(col column.Column) error {
+func DeleteColumn(col column.Column, internalTrash bool) error {
 	for _, crd := range col.Cards {
-		if err := TrashCard(crd); err != nil {
+		if err := TrashCard(crd, internalTrash); err != nil {
 			// Log or handle error, but try to continue
 			fmt.Fprintf(os.Stderr, "could not trash card %s: %v\n", crd.Path, err)
 		}
 	}
 
-	_, err := exec.LookPath("trash")
-	if err != nil {
-		return fmt.Errorf("'trash' command not found, please install trash-cli")
+	if internalTrash || !hasTrashCLI() {
+		return trashColumnDir(col)
 	}
 	cmd := exec.Command("trash", col.Path)
 	return cmd.Run()
@@ -406,7 +520,7 @@ func RenameColumn(col *column.Column, newName string) error {
 		return nil // No change
 	}
 
-	newPath := filepath.Join(DataDirName, newName)
+	newPath := ColumnDir(newName)
 	if _, err := os.Stat(newPath); !os.IsNotExist(err) {
 		return fmt.Errorf("column '%s' already exists", newName)
 	}
@@ -430,18 +544,12 @@ func statePath() string {
 	return filepath.Join(DataDirName, StateFileName)
 }
 
-func SaveState(focusedColumn, focusedCard int, doneColumn string, showHidden bool) error {
-	state := AppState{
-		FocusedColumn: focusedColumn,
-		FocusedCard:   focusedCard,
-		DoneColumn:    doneColumn,
-		ShowHidden:    showHidden,
-	}
+func SaveState(state AppState) error {
 	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(statePath(), data, 0644)
+	return WriteFileAtomic(statePath(), data, 0644)
 }
 
 func LoadState() (AppState, error) {
@@ -460,10 +568,14 @@ func LoadState() (AppState, error) {
 	return state, nil
 }
 
-func FlushTrash(trash []card.Card) error {
+func FlushTrash(trash []card.Card, internal bool) error {
 	var firstErr error
 	for _, c := range trash {
-		err := TrashCard(c)
+		// Why: The card may have been purged from the trash view already.
+		if _, err := os.Stat(c.Path); os.IsNotExist(err) {
+			continue
+		}
+		err := TrashCard(c, internal)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "could not trash card %s: %v\n", c.Path, err)
 			if firstErr == nil {
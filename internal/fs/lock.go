@@ -0,0 +1,105 @@
+// internal/fs/lock.go
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const LockFileName = "lock"
+
+// LockedError is returned by AcquireLock when another live process holds the
+// board's lock.
+type LockedError struct {
+	PID int
+}
+
+func (e *LockedError) Error() string {
+	if e.PID == 0 {
+		return "board is being locked by another kanban process"
+	}
+	return fmt.Sprintf("board is in use by another kanban process (pid %d)", e.PID)
+}
+
+// errLockHeld is returned by lockFile when another open file holds the lock.
+var errLockHeld = errors.New("lock is held")
+
+// Lock is an advisory lock on the board in a directory. It only guards
+// against other kanban processes; editors and git are not affected.
+type Lock struct {
+	file *os.File
+}
+
+// AcquireLock locks the board in the current directory.
+//
+// The lock is taken on the lock file by the operating system, which releases
+// it when the process exits, so a crashed process never leaves the board
+// locked. The file itself is never removed: a process that opened it before
+// the removal would lock a file nobody else sees. It holds the PID of the
+// owner, to name it in LockedError.
+func AcquireLock() (*Lock, error) {
+	path := filepath.Join(DataDirName, LockFileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		if errors.Is(err, errLockHeld) {
+			return nil, &LockedError{PID: readLockPID(path)}
+		}
+		return nil, fmt.Errorf("could not lock %s: %w", path, err)
+	}
+
+	if err := writeLockPID(f, os.Getpid()); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Lock{file: f}, nil
+}
+
+// Release unlocks the board. It is safe to call on a nil Lock.
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	// Why: Clear the PID while still holding the lock, so that it does not
+	// name a process that has let go of the board.
+	err := l.file.Truncate(0)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return err
+}
+
+func writeLockPID(f *os.File, pid int) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(f, "%d\n", pid)
+	return err
+}
+
+func readLockPID(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
@@ -0,0 +1,29 @@
+package fs
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestAcquireLock(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	lock, err := AcquireLock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var locked *LockedError
+	if _, err := AcquireLock(); !errors.As(err, &locked) || locked.PID != os.Getpid() {
+		t.Errorf("AcquireLock() while locked = %v; want a LockedError naming pid %d", err, os.Getpid())
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+	lock, err = AcquireLock()
+	if err != nil {
+		t.Fatalf("AcquireLock() after Release() = %v", err)
+	}
+	lock.Release()
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package fs
+
+import (
+	"errors"
+	"math"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile locks f without waiting. Closing f releases the lock.
+func lockFile(f *os.File) error {
+	// Why: Windows locks keep other processes from reading the locked bytes,
+	// so lock one far past the end of the file rather than the PID in it.
+	overlapped := windows.Overlapped{Offset: math.MaxUint32, OffsetHigh: math.MaxInt32}
+	err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, &overlapped)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errLockHeld
+	}
+	return err
+}
@@ -0,0 +1,267 @@
+// internal/fs/names.go
+package fs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ColumnDir is the directory holding the card files of the column title.
+// Boards from older versions keep a column whose title needs encoding in a
+// directory named after the title as is; that directory is used while the
+// encoded one does not exist.
+func ColumnDir(title string) string {
+	return columnDir(title, title)
+}
+
+// columnDir is ColumnDir for a column whose header wrote its title as
+// legacyName.
+func columnDir(title, legacyName string) string {
+	dir := filepath.Join(DataDirName, columnDirName(title))
+	legacy, ok := legacyColumnDir(legacyName)
+	if !ok || legacy == dir {
+		return dir
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+			return legacy
+		}
+	}
+	return dir
+}
+
+// legacyColumnDir returns the directory older versions used for a column whose
+// header wrote its title as title, if the title could name a directory at all.
+func legacyColumnDir(title string) (string, bool) {
+	if title == "" || title == "." || title == ".." || strings.ContainsRune(title, '/') || strings.ContainsRune(title, filepath.Separator) {
+		return "", false
+	}
+	return filepath.Join(DataDirName, title), true
+}
+
+// ColumnTitleOf returns the title of the column whose directory holds the
+// card file at path.
+func ColumnTitleOf(path string) string {
+	return columnTitle(filepath.Base(filepath.Dir(path)))
+}
+
+// columnDirName maps a column title to a directory name that is valid on every
+// platform and cannot be mistaken for one of the hidden directories kept in
+// the data directory. Unsafe characters are percent-encoded, so titles that
+// need no encoding keep their directory names from older versions.
+func columnDirName(title string) string {
+	var b strings.Builder
+	for i := 0; i < len(title); i++ {
+		ch := title[i]
+		unsafe := ch < 0x20 || ch == 0x7f || strings.IndexByte(`/\:*?"<>|%`, ch) >= 0 ||
+			(i == 0 && ch == '.') ||
+			// Why: Windows drops trailing dots and spaces from file names.
+			(i == len(title)-1 && (ch == '.' || ch == ' '))
+		if unsafe {
+			fmt.Fprintf(&b, "%%%02X", ch)
+		} else {
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
+
+// columnTitle reverses columnDirName. A '%' not followed by two hex digits is
+// kept as is.
+func columnTitle(dirName string) string {
+	if !strings.Contains(dirName, "%") {
+		return dirName
+	}
+	var b strings.Builder
+	for i := 0; i < len(dirName); i++ {
+		if dirName[i] == '%' && i+2 < len(dirName) && isHex(dirName[i+1]) && isHex(dirName[i+2]) {
+			b.WriteByte(unhex(dirName[i+1])<<4 | unhex(dirName[i+2]))
+			i += 2
+			continue
+		}
+		b.WriteByte(dirName[i])
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+// isMarkdownPunct reports whether a backslash before c is a Markdown escape.
+func isMarkdownPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+// isEscaped reports whether the byte at i of s is escaped by a backslash, one
+// not escaped itself.
+func isEscaped(s string, i int) bool {
+	n := 0
+	for i-n > 0 && s[i-n-1] == '\\' {
+		n++
+	}
+	return n%2 == 1
+}
+
+// unescapeMarkdown removes the backslashes escaping punctuation. Other
+// backslashes are literal, as in CommonMark.
+func unescapeMarkdown(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isMarkdownPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// escapeLinkText escapes a card title for use as link text.
+func escapeLinkText(title string) string {
+	r := strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`, "\n", " ")
+	return r.Replace(title)
+}
+
+// formatLinkDest writes a card path as a link destination, in angle brackets
+// if it holds characters a bare destination cannot.
+func formatLinkDest(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.ContainsAny(path, " ()<>\\\t") {
+		return path
+	}
+	r := strings.NewReplacer(`\`, `\\`, `<`, `\<`, `>`, `\>`)
+	return "<" + r.Replace(path) + ">"
+}
+
+// formatCardLink renders the kanban.md entry of a card.
+func formatCardLink(title, path string) string {
+	return "- [" + escapeLinkText(title) + "](" + formatLinkDest(path) + ")"
+}
+
+// looseCardLinkRegex matches card entries that do not parse as proper
+// Markdown, such as unescaped titles written by older versions. The title runs
+// up to the last "](".
+var looseCardLinkRegex = regexp.MustCompile(`^[-*+]\s+\[(.*)\]\((.+)\)\s*$`)
+
+// parseCardLink parses a card entry: a top-level list item that only holds a
+// link. It accepts escaped and nested brackets in the title, and destinations
+// in angle brackets or bare, including the spaces older versions wrote.
+func parseCardLink(line string) (title, path string, ok bool) {
+	if title, path, ok = parseMarkdownLink(line); ok {
+		return title, path, true
+	}
+	matches := looseCardLinkRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return "", "", false
+	}
+	return matches[1], strings.TrimSpace(matches[2]), true
+}
+
+func parseMarkdownLink(line string) (title, path string, ok bool) {
+	if len(line) < 2 || strings.IndexByte("-*+", line[0]) < 0 || (line[1] != ' ' && line[1] != '\t') {
+		return "", "", false
+	}
+	rest := strings.TrimLeft(line[1:], " \t")
+	if !strings.HasPrefix(rest, "[") {
+		return "", "", false
+	}
+
+	// Link text: up to the "]" closing the opening bracket.
+	depth, i := 0, 1
+	for ; i < len(rest); i++ {
+		switch rest[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+		if depth < 0 {
+			break
+		}
+	}
+	if i >= len(rest) || !strings.HasPrefix(rest[i:], "](") {
+		return "", "", false
+	}
+	title = unescapeMarkdown(rest[1:i])
+	rest = rest[i+2:]
+
+	// Destination: <...> or bare with balanced parentheses.
+	if strings.HasPrefix(rest, "<") {
+		end := -1
+		for j := 1; j < len(rest); j++ {
+			if rest[j] == '\\' {
+				j++
+			} else if rest[j] == '>' {
+				end = j
+				break
+			}
+		}
+		if end < 0 {
+			return "", "", false
+		}
+		path = unescapeMarkdown(rest[1:end])
+		rest = rest[end+1:]
+		if !strings.HasPrefix(rest, ")") {
+			return "", "", false
+		}
+		rest = rest[1:]
+	} else {
+		depth, j := 0, 0
+		for ; j < len(rest); j++ {
+			if rest[j] == '\\' {
+				j++
+				continue
+			}
+			if rest[j] == '(' {
+				depth++
+			} else if rest[j] == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+		if j >= len(rest) {
+			return "", "", false
+		}
+		path = unescapeMarkdown(strings.TrimSpace(rest[:j]))
+		rest = rest[j+1:]
+	}
+
+	if strings.TrimSpace(rest) != "" || path == "" {
+		return "", "", false
+	}
+	return title, path, true
+}
+
+// headerLimitRegex matches what parseColumnHeader would read as a WIP limit.
+var headerLimitRegex = regexp.MustCompile(`<!--\s*wip:\s*\d+\s*-->$`)
+
+// escapeColumnTitle escapes a column title for a header, so that a title
+// ending in "<!-- wip: 3 -->" is not read back as a WIP limit.
+func escapeColumnTitle(title string) string {
+	title = strings.ReplaceAll(title, `\`, `\\`)
+	if loc := headerLimitRegex.FindStringIndex(title); loc != nil {
+		title = title[:loc[0]] + `\` + title[loc[0]:]
+	}
+	return title
+}
@@ -0,0 +1,159 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kanban/internal/column"
+)
+
+func TestColumnDirName(t *testing.T) {
+	tests := []struct {
+		title, dir string
+	}{
+		{"Todo", "Todo"},
+		{"In Progress", "In Progress"},
+		{"Design/UX", "Design%2FUX"},
+		{"Q3: Plan", "Q3%3A Plan"},
+		{"100%", "100%25"},
+		{".hidden", "%2Ehidden"},
+		{"Done.", "Done%2E"},
+		{"Done ", "Done%20"},
+		{`a\b*c?"<>|`, `a%5Cb%2Ac%3F%22%3C%3E%7C`},
+		{"tab\there", "tab%09here"},
+	}
+	for _, tt := range tests {
+		if got := columnDirName(tt.title); got != tt.dir {
+			t.Errorf("columnDirName(%q) = %q; want %q", tt.title, got, tt.dir)
+		}
+		if got := columnTitle(tt.dir); got != tt.title {
+			t.Errorf("columnTitle(%q) = %q; want %q", tt.dir, got, tt.title)
+		}
+	}
+}
+
+func TestColumnTitleKeepsStrayPercent(t *testing.T) {
+	for _, dir := range []string{"50% done", "%", "%4", "%zz"} {
+		if got := columnTitle(dir); got != dir {
+			t.Errorf("columnTitle(%q) = %q; want it unchanged", dir, got)
+		}
+	}
+}
+
+func TestColumnDirUsesLegacyDirectory(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if got, want := ColumnDir("Q3: Plan"), filepath.Join(DataDirName, "Q3%3A Plan"); got != want {
+		t.Errorf("ColumnDir() without directories = %q; want %q", got, want)
+	}
+
+	legacy := filepath.Join(DataDirName, "Q3: Plan")
+	if err := os.MkdirAll(legacy, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := ColumnDir("Q3: Plan"); got != legacy {
+		t.Errorf("ColumnDir() with a legacy directory = %q; want %q", got, legacy)
+	}
+
+	encoded := filepath.Join(DataDirName, "Q3%3A Plan")
+	if err := os.MkdirAll(encoded, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := ColumnDir("Q3: Plan"); got != encoded {
+		t.Errorf("ColumnDir() with both directories = %q; want %q", got, encoded)
+	}
+
+	if got, want := ColumnDir("Design/UX"), filepath.Join(DataDirName, "Design%2FUX"); got != want {
+		t.Errorf("ColumnDir(%q) = %q; want %q", "Design/UX", got, want)
+	}
+}
+
+func TestCardLinkRoundTrip(t *testing.T) {
+	tests := []struct {
+		title, path string
+	}{
+		{"Plain", ".kanban/Todo/1.md"},
+		{"[bracketed] title", ".kanban/Todo/1.md"},
+		{"a ] b [ c", ".kanban/Todo/1.md"},
+		{`back\slash`, ".kanban/Todo/1.md"},
+		{"link [x](y)", ".kanban/Todo/1.md"},
+		{"Spaces", ".kanban/In Progress/1.md"},
+		{"Parens", ".kanban/Sprint (3)/1.md"},
+		{"Angle", ".kanban/a<b>/1.md"},
+	}
+	for _, tt := range tests {
+		line := formatCardLink(tt.title, tt.path)
+		title, path, ok := parseCardLink(line)
+		if !ok || title != tt.title || path != tt.path {
+			t.Errorf("parseCardLink(%q) = %q, %q, %v; want %q, %q", line, title, path, ok, tt.title, tt.path)
+		}
+	}
+}
+
+func TestParseCardLink(t *testing.T) {
+	tests := []struct {
+		line, title, path string
+		ok                bool
+	}{
+		{"- [Title](.kanban/Todo/1.md)", "Title", ".kanban/Todo/1.md", true},
+		{"* [Title](.kanban/Todo/1.md)", "Title", ".kanban/Todo/1.md", true},
+		{"+   [Title](<.kanban/In Progress/1.md>)", "Title", ".kanban/In Progress/1.md", true},
+		// Written by older versions without escaping.
+		{"- [a [b] c](.kanban/In Progress/1.md)", "a [b] c", ".kanban/In Progress/1.md", true},
+		{"- Title", "", "", false},
+		{"  - [Title](.kanban/Todo/1.md)", "", "", false},
+		{"-[Title](.kanban/Todo/1.md)", "", "", false},
+	}
+	for _, tt := range tests {
+		title, path, ok := parseCardLink(tt.line)
+		if ok != tt.ok || title != tt.title || path != tt.path {
+			t.Errorf("parseCardLink(%q) = %q, %q, %v; want %q, %q, %v", tt.line, title, path, ok, tt.title, tt.path, tt.ok)
+		}
+	}
+}
+
+func TestColumnHeaderRoundTrip(t *testing.T) {
+	tests := []struct {
+		title string
+		limit int
+	}{
+		{"Todo", 0},
+		{"In Progress", 3},
+		{"Sprint (2024)", 0},
+		{"Sprint (2024)", 2},
+		{`back\slash`, 0},
+		{"Fake <!-- wip: 4 -->", 0},
+		{"Fake <!-- wip: 4 -->", 5},
+	}
+	for _, tt := range tests {
+		header := formatColumnHeader(column.Column{Title: tt.title, Limit: tt.limit})
+		title, limit := parseColumnHeader(header[len("# ") : len(header)-1])
+		if title != tt.title || limit != tt.limit {
+			t.Errorf("header %q read as %q, %d; want %q, %d", header, title, limit, tt.title, tt.limit)
+		}
+	}
+}
+
+func TestLegacyDirectoryOfEscapedHeader(t *testing.T) {
+	t.Chdir(t.TempDir())
+	// Why: Older versions named the directory after the header as written.
+	legacy := filepath.Join(DataDirName, `a\_b`)
+	if err := os.MkdirAll(legacy, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(BoardFileName, []byte("# a\\_b <!-- wip: 2 -->\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := LoadBoard()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if col := b.FindColumn("a_b"); col == nil || col.Path != legacy {
+		t.Errorf("loaded column = %+v; want a_b in %q", col, legacy)
+	}
+	if problems, err := CheckBoard(); err != nil || len(problems) > 0 {
+		t.Errorf("CheckBoard() = %v, %v; want no problems", problems, err)
+	}
+}
@@ -0,0 +1,137 @@
+package fs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"kanban/internal/board"
+	"kanban/internal/card"
+	"kanban/internal/column"
+)
+
+// RestoreBoard brings the card files and column directories on disk in line
+// with target, a board snapshot taken before or after current. Cards are moved
+// back into their column directories, recreated from the snapshot if their
+// file is gone and rewritten if their data differs. Cards that only exist in
+// current are added to target's trash, and column directories target no
+// longer has are removed once they are empty. kanban.md itself is left to the
+// caller.
+func RestoreBoard(current board.Board, target *board.Board) error {
+	var errs []string
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+
+	targetCols := allColumns(*target)
+	for _, col := range targetCols {
+		if len(col.Cards) == 0 && col.Title == ArchiveColumnName {
+			continue
+		}
+		if err := os.MkdirAll(col.Path, 0755); err != nil {
+			fail("could not create column %s: %v", col.Title, err)
+		}
+	}
+
+	onDisk, err := scanCardFiles()
+	if err != nil {
+		return err
+	}
+	currentCards := indexCards(current)
+
+	wanted := make(map[string]struct{})
+	for _, col := range targetCols {
+		for _, c := range col.Cards {
+			wanted[c.UUID] = struct{}{}
+			var path string
+			if paths := onDisk[c.UUID]; len(paths) > 0 {
+				path = paths[0]
+			}
+			if err := restoreCard(c, path, currentCards[c.UUID]); err != nil {
+				fail("could not restore card %s: %v", c.Title, err)
+			}
+		}
+	}
+	// Why: Cards pending deletion stay wherever they are; recreating them would
+	// only trash them again on exit.
+	for _, c := range target.Trash {
+		wanted[c.UUID] = struct{}{}
+	}
+
+	for uuid := range currentCards {
+		if _, ok := wanted[uuid]; ok {
+			continue
+		}
+		// Why: The card may not come from the journal at all, e.g. one added by
+		// hand or re-linked by the doctor, so it is only deleted on exit and
+		// can still be restored from the trash view.
+		if paths := onDisk[uuid]; len(paths) > 0 {
+			c := currentCards[uuid]
+			c.Path = paths[0]
+			target.Trash = append(target.Trash, c)
+		}
+	}
+
+	targetPaths := make(map[string]struct{})
+	for _, col := range targetCols {
+		targetPaths[filepath.Clean(col.Path)] = struct{}{}
+	}
+	for _, col := range allColumns(current) {
+		if _, ok := targetPaths[filepath.Clean(col.Path)]; ok {
+			continue
+		}
+		// Only removes the directory if it is empty, which it is unless
+		// something outside the board put files there.
+		os.Remove(col.Path)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func restoreCard(c card.Card, foundAt string, current card.Card) error {
+	if foundAt == "" {
+		return writeCardFile(c)
+	}
+	if filepath.Clean(foundAt) != filepath.Clean(c.Path) {
+		if err := os.Rename(foundAt, c.Path); err != nil {
+			return err
+		}
+	}
+	if current.UUID == "" || !SameCardData(c, current) {
+		return writeCardFile(c)
+	}
+	return nil
+}
+
+// SameCardData reports whether a and b would be written as the same file,
+// ignoring where they live.
+func SameCardData(a, b card.Card) bool {
+	a.Path, b.Path = "", ""
+	a.Size, b.Size = 0, 0
+	a.Annotation, b.Annotation = nil, nil
+	return reflect.DeepEqual(a, b)
+}
+
+func allColumns(b board.Board) []column.Column {
+	cols := make([]column.Column, 0, len(b.Columns)+1)
+	cols = append(cols, b.Columns...)
+	return append(cols, b.Archived)
+}
+
+func indexCards(b board.Board) map[string]card.Card {
+	cards := make(map[string]card.Card)
+	for _, col := range allColumns(b) {
+		for _, c := range col.Cards {
+			cards[c.UUID] = c
+		}
+	}
+	for _, c := range b.Trash {
+		cards[c.UUID] = c
+	}
+	return cards
+}
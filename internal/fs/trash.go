@@ -0,0 +1,134 @@
+// internal/fs/trash.go
+package fs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"kanban/internal/card"
+	"kanban/internal/column"
+)
+
+// TrashDirName is the internal trash inside the data directory. It is used
+// when trash-cli is not installed or when the board is configured for it.
+const TrashDirName = ".trash"
+
+// TrashedCard is a card in the internal trash.
+type TrashedCard struct {
+	Card card.Card `json:"-"`
+	// Column is the title of the column the card was deleted from.
+	Column    string    `json:"column"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+func trashDir() string {
+	return filepath.Join(DataDirName, TrashDirName)
+}
+
+// trashMetaPath is the file next to a trashed card that records where it came from.
+func trashMetaPath(cardPath string) string {
+	return strings.TrimSuffix(cardPath, ".md") + ".json"
+}
+
+// moveToInternalTrash moves the card file into the internal trash, recording
+// the column it was deleted from.
+func moveToInternalTrash(c card.Card) error {
+	if err := os.MkdirAll(trashDir(), 0755); err != nil {
+		return err
+	}
+
+	meta := TrashedCard{
+		Column:    ColumnTitleOf(c.Path),
+		DeletedAt: time.Now(),
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dest := filepath.Join(trashDir(), filepath.Base(c.Path))
+	if err := WriteFileAtomic(trashMetaPath(dest), data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(c.Path, dest); err != nil {
+		os.Remove(trashMetaPath(dest))
+		return err
+	}
+	return nil
+}
+
+// LoadTrash lists the cards in the internal trash, most recently deleted first.
+func LoadTrash() ([]TrashedCard, error) {
+	matches, err := filepath.Glob(filepath.Join(trashDir(), "*.md"))
+	if err != nil {
+		return nil, err
+	}
+
+	trashed := make([]TrashedCard, 0, len(matches))
+	for _, path := range matches {
+		c, err := LoadCard(path)
+		if err != nil {
+			continue
+		}
+		t := TrashedCard{Card: c}
+		if data, err := os.ReadFile(trashMetaPath(path)); err == nil {
+			json.Unmarshal(data, &t)
+		}
+		trashed = append(trashed, t)
+	}
+
+	sort.SliceStable(trashed, func(i, j int) bool {
+		return trashed[i].DeletedAt.After(trashed[j].DeletedAt)
+	})
+	return trashed, nil
+}
+
+// RestoreTrashedCard moves a card out of the internal trash into col and
+// returns it with its new path.
+func RestoreTrashedCard(t TrashedCard, col column.Column) (card.Card, error) {
+	if err := os.MkdirAll(col.Path, 0755); err != nil {
+		return card.Card{}, err
+	}
+	c := t.Card
+	dest := filepath.Join(col.Path, filepath.Base(c.Path))
+	if _, err := os.Stat(dest); err == nil {
+		return card.Card{}, fmt.Errorf("%s already exists", dest)
+	}
+	if err := os.Rename(c.Path, dest); err != nil {
+		return card.Card{}, err
+	}
+	os.Remove(trashMetaPath(c.Path))
+	c.Path = dest
+	return c, nil
+}
+
+// PurgeTrashedCard permanently deletes a card from the internal trash.
+func PurgeTrashedCard(t TrashedCard) error {
+	if err := os.Remove(t.Card.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(trashMetaPath(t.Card.Path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// trashColumnDir disposes of a column directory whose cards have already
+// been trashed. Leftover files that are not cards are kept in the internal
+// trash rather than deleted.
+func trashColumnDir(col column.Column) error {
+	err := os.Remove(col.Path)
+	if err == nil || os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.MkdirAll(trashDir(), 0755); err != nil {
+		return err
+	}
+	dest := filepath.Join(trashDir(), fmt.Sprintf("%s-%d", filepath.Base(col.Path), time.Now().Unix()))
+	return os.Rename(col.Path, dest)
+}
@@ -1,63 +1,392 @@
 package history
 
-import "kanban/internal/board"
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"time"
+
+	"kanban/internal/board"
+	"kanban/internal/card"
+	"kanban/internal/column"
+	"kanban/internal/fs"
+)
 
 const maxHistorySize = 100
 
+// Entry is one journaled mutation, kept as what it changed: Before is undone
+// by applying it to the board, After is redone the same way. The journal thus
+// grows with the size of the changes, not with the size of the board.
+type Entry struct {
+	Op     string    `json:"op"`
+	Time   time.Time `json:"time"`
+	Before Change    `json:"before"`
+	After  Change    `json:"after"`
+}
+
+// Change is the part of the board a mutation touched, as it was on one side
+// of the mutation. Parts it left alone are nil.
+type Change struct {
+	// Columns is the column layout, without cards, if it changed.
+	Columns *[]column.Column `json:"columns,omitempty"`
+	// Order is the card UUIDs of each column whose cards changed, by title.
+	Order map[string][]string `json:"order,omitempty"`
+	// Cards holds the cards that were added, removed or edited, by UUID.
+	Cards map[string]card.Card `json:"cards,omitempty"`
+	// Trash is the UUIDs of the cards pending deletion, if they changed.
+	Trash *[]string `json:"trash,omitempty"`
+}
+
 type History struct {
-	undoStack []board.Board
-	redoStack []board.Board
+	undoStack []Entry
+	redoStack []Entry
+	// pending is the board before the mutation under way; its changes are
+	// taken once the board after it is seen.
+	pending   *board.Board
+	pendingOp string
+	// path is where the journal is persisted; empty keeps it in memory only.
+	path string
+}
+
+type journalFile struct {
+	Undo []Entry `json:"undo"`
+	Redo []Entry `json:"redo"`
 }
 
 func New() *History {
 	return &History{
-		undoStack: make([]board.Board, 0, maxHistorySize),
-		redoStack: make([]board.Board, 0, maxHistorySize),
+		undoStack: make([]Entry, 0, maxHistorySize),
+		redoStack: make([]Entry, 0, maxHistorySize),
+	}
+}
+
+// Load reads the journal persisted at path. A missing file yields an empty
+// history that will be saved to path. The journal is only read and written
+// while holding the board's lock, so no other process changes it meanwhile.
+func Load(path string) (*History, error) {
+	h := New()
+	h.path = path
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return h, err
+	}
+
+	var jf journalFile
+	if err := json.Unmarshal(data, &jf); err != nil {
+		return h, err
+	}
+	// Why: Entries of older versions held whole boards and read back empty.
+	for _, e := range jf.Undo {
+		if !e.empty() {
+			h.undoStack = append(h.undoStack, e)
+		}
+	}
+	for _, e := range jf.Redo {
+		if !e.empty() {
+			h.redoStack = append(h.redoStack, e)
+		}
 	}
+	return h, nil
 }
 
-func (h *History) Push(b board.Board) {
-	if len(h.undoStack) >= maxHistorySize {
-		h.undoStack = h.undoStack[1:]
+// Save persists the journal so undo history survives restarts. current is
+// the board as it is now, which completes the mutation under way.
+func (h *History) Save(current board.Board) error {
+	h.commit(current)
+	if h.path == "" {
+		return nil
 	}
-	h.undoStack = append(h.undoStack, b.DeepCopy())
+	data, err := json.Marshal(journalFile{Undo: h.undoStack, Redo: h.redoStack})
+	if err != nil {
+		return err
+	}
+	return fs.WriteFileAtomic(h.path, data, 0644)
+}
+
+// Push starts the mutation op of b. What it changed is recorded when the
+// board after it is passed to the next Push, Undo, Redo or Save.
+func (h *History) Push(b board.Board, op string) {
+	h.commit(b)
+	before := b.DeepCopy()
+	h.pending = &before
+	h.pendingOp = op
+}
+
+// Drop forgets the mutation under way, for a command that changed nothing.
+func (h *History) Drop() {
+	h.pending = nil
+}
+
+// Clear forgets all history, e.g. after the board was replaced by the one on
+// disk, which the journaled changes no longer apply to.
+func (h *History) Clear() {
+	h.undoStack = h.undoStack[:0]
 	h.redoStack = h.redoStack[:0]
+	h.pending = nil
 }
 
-func (h *History) Undo(current board.Board) (board.Board, bool) {
+// Undo returns the board current becomes when the last mutation is reverted,
+// and the name of that mutation.
+func (h *History) Undo(current board.Board) (board.Board, string, bool) {
+	h.commit(current)
 	if len(h.undoStack) == 0 {
-		return board.Board{}, false
+		return board.Board{}, "", false
 	}
 
-	previousState := h.undoStack[len(h.undoStack)-1]
+	e := h.undoStack[len(h.undoStack)-1]
 	h.undoStack = h.undoStack[:len(h.undoStack)-1]
+	h.redoStack = pushBounded(h.redoStack, e)
+	return e.Before.apply(current, e.After), e.Op, true
+}
 
-	if len(h.redoStack) >= maxHistorySize {
-		h.redoStack = h.redoStack[1:]
+// Redo returns the board current becomes when the last undone mutation is
+// reapplied, and the name of that mutation.
+func (h *History) Redo(current board.Board) (board.Board, string, bool) {
+	h.commit(current)
+	if len(h.redoStack) == 0 {
+		return board.Board{}, "", false
 	}
-	h.redoStack = append(h.redoStack, current.DeepCopy())
 
-	return previousState, true
+	e := h.redoStack[len(h.redoStack)-1]
+	h.redoStack = h.redoStack[:len(h.redoStack)-1]
+	h.undoStack = pushBounded(h.undoStack, e)
+	return e.After.apply(current, e.Before), e.Op, true
 }
 
-func (h *History) Redo(current board.Board) (board.Board, bool) {
-	if len(h.redoStack) == 0 {
-		return board.Board{}, false
+// commit records the mutation under way, now that after shows its result. A
+// mutation that changed nothing is not recorded and keeps the redo stack.
+func (h *History) commit(after board.Board) {
+	if h.pending == nil {
+		return
+	}
+	e := diff(*h.pending, after)
+	e.Op = h.pendingOp
+	e.Time = time.Now()
+	h.pending = nil
+	if e.empty() {
+		return
+	}
+	h.undoStack = pushBounded(h.undoStack, e)
+	h.redoStack = h.redoStack[:0]
+}
+
+func pushBounded(stack []Entry, e Entry) []Entry {
+	if len(stack) >= maxHistorySize {
+		stack = stack[1:]
 	}
+	return append(stack, e)
+}
 
-	nextState := h.redoStack[len(h.redoStack)-1]
-	h.redoStack = h.redoStack[:len(h.redoStack)-1]
+func (e Entry) empty() bool {
+	return e.Before.empty() && e.After.empty()
+}
+
+func (c Change) empty() bool {
+	return c.Columns == nil && len(c.Order) == 0 && len(c.Cards) == 0 && c.Trash == nil
+}
+
+// diff returns the changes that turn before into after.
+func diff(before, after board.Board) Entry {
+	var e Entry
+
+	beforeLayout, afterLayout := layout(before), layout(after)
+	if !reflect.DeepEqual(beforeLayout, afterLayout) {
+		e.Before.Columns = &beforeLayout
+		e.After.Columns = &afterLayout
+	}
 
-	if len(h.undoStack) >= maxHistorySize {
-		h.undoStack = h.undoStack[1:]
+	beforeOrder, afterOrder := orders(before), orders(after)
+	for title, uuids := range beforeOrder {
+		if other, ok := afterOrder[title]; !ok || !reflect.DeepEqual(uuids, other) {
+			e.Before.Order = setOrder(e.Before.Order, title, uuids)
+		}
+	}
+	for title, uuids := range afterOrder {
+		if other, ok := beforeOrder[title]; !ok || !reflect.DeepEqual(uuids, other) {
+			e.After.Order = setOrder(e.After.Order, title, uuids)
+		}
 	}
-	h.undoStack = append(h.undoStack, current.DeepCopy())
 
-	return nextState, true
+	beforeCards, afterCards := cardsByUUID(before), cardsByUUID(after)
+	for uuid, c := range beforeCards {
+		if other, ok := afterCards[uuid]; !ok || !reflect.DeepEqual(c, other) {
+			e.Before.Cards = setCard(e.Before.Cards, c)
+		}
+	}
+	for uuid, c := range afterCards {
+		if other, ok := beforeCards[uuid]; !ok || !reflect.DeepEqual(c, other) {
+			e.After.Cards = setCard(e.After.Cards, c)
+		}
+	}
+
+	beforeTrash, afterTrash := uuidsOf(before.Trash), uuidsOf(after.Trash)
+	if !reflect.DeepEqual(beforeTrash, afterTrash) {
+		e.Before.Trash = &beforeTrash
+		e.After.Trash = &afterTrash
+	}
+	return e
 }
 
-func (h *History) Drop() {
-	if len(h.undoStack) > 0 {
-		h.undoStack = h.undoStack[:len(h.undoStack)-1]
+// apply returns current with the parts c holds put back, where other is the
+// other side of the mutation. Cards are looked up in c first and then
+// wherever they are on current, so that a card moved by the mutation is taken
+// back from its new place. Cards the mutation did not touch stay where they
+// are, even in a column whose order c puts back.
+func (c Change) apply(current board.Board, other Change) board.Board {
+	target := current.DeepCopy()
+	cards := cardsByUUID(current)
+	for uuid, crd := range c.Cards {
+		cards[uuid] = crd
+	}
+	lookup := func(uuids []string) []card.Card {
+		found := make([]card.Card, 0, len(uuids))
+		for _, uuid := range uuids {
+			if crd, ok := cards[uuid]; ok {
+				found = append(found, crd)
+			}
+		}
+		return found
+	}
+
+	if c.Columns != nil {
+		existing := make(map[string]column.Column, len(target.Columns))
+		for _, col := range target.Columns {
+			existing[col.Title] = col
+		}
+		target.Columns = make([]column.Column, len(*c.Columns))
+		for i, col := range *c.Columns {
+			col.Cards = []card.Card{}
+			if cur, ok := existing[col.Title]; ok {
+				col.Cards = cur.Cards
+			}
+			target.Columns[i] = col
+		}
+	}
+
+	ordered := make(map[string]struct{})
+	for _, uuids := range c.Order {
+		for _, uuid := range uuids {
+			ordered[uuid] = struct{}{}
+		}
+	}
+	placed := make(map[string]struct{})
+	for _, col := range columnsOf(&target) {
+		var kept []card.Card
+		if uuids, ok := c.Order[col.Title]; ok {
+			kept = lookup(uuids)
+		}
+		for _, crd := range col.Cards {
+			if _, ok := ordered[crd.UUID]; ok {
+				continue
+			}
+			if _, ok := c.Order[col.Title]; ok && contains(other.Order[col.Title], crd.UUID) {
+				continue
+			}
+			kept = append(kept, crd)
+		}
+		if kept == nil {
+			kept = []card.Card{}
+		}
+		for i, crd := range kept {
+			if changed, ok := c.Cards[crd.UUID]; ok {
+				kept[i] = changed
+			}
+			placed[crd.UUID] = struct{}{}
+		}
+		col.Cards = kept
+	}
+
+	if c.Trash != nil {
+		target.Trash = lookup(*c.Trash)
+	}
+	// Why: A card the change put back on the board is no longer pending
+	// deletion, even if the trash itself was left alone by the mutation.
+	trash := target.Trash[:0]
+	for _, crd := range target.Trash {
+		if _, ok := placed[crd.UUID]; ok {
+			continue
+		}
+		if changed, ok := c.Cards[crd.UUID]; ok {
+			crd = changed
+		}
+		trash = append(trash, crd)
+	}
+	target.Trash = trash
+	return target
+}
+
+// layout returns the columns of b without their cards.
+func layout(b board.Board) []column.Column {
+	cols := make([]column.Column, len(b.Columns))
+	for i, col := range b.Columns {
+		col.Cards = nil
+		cols[i] = col
+	}
+	return cols
+}
+
+func columnsOf(b *board.Board) []*column.Column {
+	cols := make([]*column.Column, 0, len(b.Columns)+1)
+	for i := range b.Columns {
+		cols = append(cols, &b.Columns[i])
+	}
+	return append(cols, &b.Archived)
+}
+
+func orders(b board.Board) map[string][]string {
+	o := make(map[string][]string, len(b.Columns)+1)
+	for _, col := range columnsOf(&b) {
+		o[col.Title] = uuidsOf(col.Cards)
+	}
+	return o
+}
+
+func cardsByUUID(b board.Board) map[string]card.Card {
+	cards := make(map[string]card.Card)
+	for _, col := range columnsOf(&b) {
+		for _, c := range col.Cards {
+			cards[c.UUID] = c
+		}
+	}
+	for _, c := range b.Trash {
+		cards[c.UUID] = c
+	}
+	return cards
+}
+
+func uuidsOf(cards []card.Card) []string {
+	uuids := make([]string, len(cards))
+	for i, c := range cards {
+		uuids[i] = c.UUID
+	}
+	return uuids
+}
+
+func contains(uuids []string, uuid string) bool {
+	for _, u := range uuids {
+		if u == uuid {
+			return true
+		}
+	}
+	return false
+}
+
+func setOrder(o map[string][]string, title string, uuids []string) map[string][]string {
+	if o == nil {
+		o = make(map[string][]string)
+	}
+	o[title] = uuids
+	return o
+}
+
+func setCard(cards map[string]card.Card, c card.Card) map[string]card.Card {
+	if cards == nil {
+		cards = make(map[string]card.Card)
 	}
+	cards[c.UUID] = c
+	return cards
 }
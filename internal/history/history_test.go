@@ -0,0 +1,213 @@
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"kanban/internal/board"
+	"kanban/internal/card"
+	"kanban/internal/column"
+)
+
+func newBoard(cols ...column.Column) board.Board {
+	b := board.New("/board", cols)
+	b.Archived = column.New("Archived", ".kanban/Archived")
+	return b
+}
+
+func col(title string, cards ...card.Card) column.Column {
+	return column.New(title, ".kanban/"+title, cards...)
+}
+
+func crd(uuid, title string) card.Card {
+	return card.Card{UUID: uuid, Title: title, Path: ".kanban/" + uuid + ".md"}
+}
+
+// summary renders the columns, cards and trash of b for comparison.
+func summary(b board.Board) string {
+	var s strings.Builder
+	for _, c := range append(append([]column.Column(nil), b.Columns...), b.Archived) {
+		s.WriteString(c.Title + ":")
+		for _, cd := range c.Cards {
+			s.WriteString(" " + cd.Title + "[" + strings.Join(cd.Tags, ",") + "]")
+		}
+		s.WriteString("; ")
+	}
+	s.WriteString("trash:")
+	for _, cd := range b.Trash {
+		s.WriteString(" " + cd.Title)
+	}
+	return s.String()
+}
+
+func TestUndoRedo(t *testing.T) {
+	a, b, c := crd("1", "a"), crd("2", "b"), crd("3", "c")
+	tagged := b
+	tagged.Tags = []string{"x"}
+
+	tests := []struct {
+		name          string
+		before, after board.Board
+	}{
+		{
+			name:   "add",
+			before: newBoard(col("Todo", a), col("Done")),
+			after:  newBoard(col("Todo", b, a), col("Done")),
+		},
+		{
+			name:   "move",
+			before: newBoard(col("Todo", a, b), col("Done", c)),
+			after:  newBoard(col("Todo", b), col("Done", c, a)),
+		},
+		{
+			name:   "edit",
+			before: newBoard(col("Todo", a, b)),
+			after:  newBoard(col("Todo", a, tagged)),
+		},
+		{
+			name:   "rename column",
+			before: newBoard(col("Todo", a, b), col("Done")),
+			after:  newBoard(col("Doing", a, b), col("Done")),
+		},
+		{
+			name:   "delete column",
+			before: newBoard(col("Todo", a), col("Done")),
+			after:  newBoard(col("Todo", a)),
+		},
+		{
+			name:   "reorder columns",
+			before: newBoard(col("Todo", a), col("Done", b)),
+			after:  newBoard(col("Done", b), col("Todo", a)),
+		},
+		{
+			name:   "delete",
+			before: newBoard(col("Todo", a, b)),
+			after: func() board.Board {
+				bd := newBoard(col("Todo", b))
+				bd.Trash = []card.Card{a}
+				return bd
+			}(),
+		},
+		{
+			name:   "archive",
+			before: newBoard(col("Todo", a, b)),
+			after: func() board.Board {
+				bd := newBoard(col("Todo", b))
+				bd.Archived.Cards = []card.Card{a}
+				return bd
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New()
+			h.Push(tt.before, tt.name)
+
+			undone, op, ok := h.Undo(tt.after)
+			if !ok || op != tt.name {
+				t.Fatalf("Undo() = %q, %v; want %q, true", op, ok, tt.name)
+			}
+			if got, want := summary(undone), summary(tt.before); got != want {
+				t.Errorf("Undo() board = %s; want %s", got, want)
+			}
+
+			redone, _, ok := h.Redo(undone)
+			if !ok {
+				t.Fatal("Redo() found nothing to redo")
+			}
+			if got, want := summary(redone), summary(tt.after); got != want {
+				t.Errorf("Redo() board = %s; want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestUndoKeepsUnrelatedChanges(t *testing.T) {
+	a, b, c := crd("1", "a"), crd("2", "b"), crd("3", "c")
+	h := New()
+	h.Push(newBoard(col("Todo", a), col("Done", b)), "move")
+	after := newBoard(col("Todo"), col("Done", b, a))
+
+	// Why: A card added after the move must survive undoing it.
+	current := after.DeepCopy()
+	current.Columns[1].Cards = append(current.Columns[1].Cards, c)
+	h.commit(after)
+
+	undone, _, _ := h.Undo(current)
+	if got, want := summary(undone), "Todo: a[]; Done: b[] c[]; Archived:; trash:"; got != want {
+		t.Errorf("Undo() board = %s; want %s", got, want)
+	}
+}
+
+func TestUndoOfAddDoesNotDropTrash(t *testing.T) {
+	a, b := crd("1", "a"), crd("2", "b")
+	h := New()
+	h.Push(newBoard(col("Todo", a)), "create card")
+	undone, _, _ := h.Undo(newBoard(col("Todo", b, a)))
+
+	// Why: The undone card leaves the board and is trashed by RestoreBoard;
+	// redoing must take it back out of the trash.
+	undone.Trash = append(undone.Trash, b)
+	redone, _, _ := h.Redo(undone)
+	if got, want := summary(redone), "Todo: b[] a[]; Archived:; trash:"; got != want {
+		t.Errorf("Redo() board = %s; want %s", got, want)
+	}
+}
+
+func TestUnchangedMutationIsNotRecorded(t *testing.T) {
+	a := crd("1", "a")
+	before, after := newBoard(col("Todo")), newBoard(col("Todo", a))
+	h := New()
+	h.Push(before, "create card")
+	undone, _, _ := h.Undo(after)
+
+	h.Push(undone, "sort")
+	if _, _, ok := h.Redo(undone); !ok {
+		t.Error("a mutation that changed nothing cleared the redo stack")
+	}
+	if _, _, ok := h.Undo(after); !ok {
+		t.Error("Undo() after Redo() found nothing to undo")
+	}
+	if _, _, ok := h.Undo(before); ok {
+		t.Error("the unchanged mutation was recorded")
+	}
+}
+
+func TestDrop(t *testing.T) {
+	h := New()
+	h.Push(newBoard(col("Todo")), "create card")
+	h.Drop()
+	if _, _, ok := h.Undo(newBoard(col("Todo", crd("1", "a")))); ok {
+		t.Error("Undo() found a dropped mutation")
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	a := crd("1", "a")
+	before, after := newBoard(col("Todo")), newBoard(col("Todo", a))
+
+	h, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Push(before, "create card")
+	if err := h.Save(after); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.undoStack[0].After, h.undoStack[0].After) {
+		t.Errorf("loaded entry = %+v; want %+v", loaded.undoStack[0], h.undoStack[0])
+	}
+	undone, op, ok := loaded.Undo(after)
+	if !ok || op != "create card" || summary(undone) != summary(before) {
+		t.Errorf("Undo() after Load() = %s, %q, %v", summary(undone), op, ok)
+	}
+}
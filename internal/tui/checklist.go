@@ -0,0 +1,177 @@
+// internal/tui/checklist.go
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"kanban/internal/card"
+	"kanban/internal/fs"
+)
+
+var (
+	checklistStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("245"))
+
+	checklistDoneStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("76")).
+				Bold(true)
+
+	checklistCursorStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("231")).
+				Background(lipgloss.Color("205"))
+)
+
+// renderChecklistBadge shows how many checklist items of c are done, or ""
+// if c has no checklist.
+func renderChecklistBadge(c card.Card) string {
+	done, total := c.ChecklistProgress()
+	if total == 0 {
+		return ""
+	}
+	badge := fmt.Sprintf("☑ %d/%d", done, total)
+	if done == total {
+		return checklistDoneStyle.Render(badge)
+	}
+	return checklistStyle.Render(badge)
+}
+
+// previewCard returns the displayed card the preview shows, or nil while a
+// column header is focused.
+func (m *Model) previewCard() *card.Card {
+	if len(m.displayColumns) == 0 {
+		return nil
+	}
+	col := m.displayColumns[m.focusedColumn]
+	focus := m.currentFocusedCard()
+	if focus == 0 || focus > len(col.Cards) {
+		return nil
+	}
+	return &col.Cards[focus-1]
+}
+
+// checklistCursor is the checklist item under the cursor in the preview.
+// Like the scroll position, it is reset when focus moves to another card.
+func (m *Model) checklistCursor() int {
+	c := m.previewCard()
+	if c == nil || m.checklistKey != m.previewKey() {
+		return 0
+	}
+	if m.checklistIndex >= len(c.Checklist) {
+		return len(c.Checklist) - 1
+	}
+	return m.checklistIndex
+}
+
+// moveChecklistCursor moves the cursor delta items through the checklist of
+// the previewed card, wrapping around, and scrolls the item into view.
+func (m *Model) moveChecklistCursor(delta int) tea.Cmd {
+	c := m.previewCard()
+	if !m.preview || c == nil || len(c.Checklist) == 0 {
+		return nil
+	}
+	n := len(c.Checklist)
+	m.checklistIndex = ((m.checklistCursor()+delta)%n + n) % n
+	m.checklistKey = m.previewKey()
+
+	width, height := m.previewSize()
+	_, row := previewLayout(m, width-previewStyle.GetHorizontalPadding())
+	if offset := m.previewOffset(); row >= 0 && row < offset {
+		m.scrollPreview(row - offset)
+	} else if row >= offset+height {
+		m.scrollPreview(row - offset - height + 1)
+	}
+	return nil
+}
+
+// toggleChecklistItem checks or unchecks item i of the previewed card. With
+// autodone set, completing the checklist moves the card to the done column.
+func (m *Model) toggleChecklistItem(i int) tea.Cmd {
+	if m.readOnly {
+		return m.refuseReadOnly()
+	}
+	shown := m.previewCard()
+	if shown == nil || len(shown.Checklist) == 0 {
+		m.statusMessage = "Card has no checklist"
+		return clearStatusCmd(2 * time.Second)
+	}
+	if i < 0 || i >= len(shown.Checklist) {
+		m.statusMessage = fmt.Sprintf("Checklist has %d items", len(shown.Checklist))
+		return clearStatusCmd(2 * time.Second)
+	}
+	c := m.findCard(shown.UUID)
+	if c == nil {
+		return nil
+	}
+
+	m.saveStateForUndo("checklist")
+	if !c.ToggleChecklistItem(i) {
+		m.history.Drop()
+		return nil
+	}
+	m.checklistIndex = i
+	m.checklistKey = c.UUID
+	if err := fs.WriteCard(*c); err != nil {
+		m.updateDisplayColumns()
+		return m.writeFailed(err)
+	}
+
+	item := c.Checklist[i]
+	done, total := c.ChecklistProgress()
+	verb := "Unchecked"
+	if item.Done {
+		verb = "Checked"
+	}
+	m.statusMessage = fmt.Sprintf("%s '%s' (%d/%d)", verb, item.Text, done, total)
+
+	var err error
+	if m.autoDone && item.Done && done == total {
+		err = m.completeChecklist(c)
+	}
+	m.updateDisplayColumns()
+	m.clampFocusedCard()
+	if err != nil {
+		return m.writeFailed(err)
+	}
+	return clearStatusCmd(3 * time.Second)
+}
+
+// completeChecklist moves c, whose checklist was just completed, to the done
+// column unless it is already there or the column is full.
+func (m *Model) completeChecklist(c *card.Card) error {
+	destCol := m.board.FindColumn(m.doneColumn())
+	archived := indexOfCard(m.board.Archived.Cards, c.UUID) < len(m.board.Archived.Cards)
+	if m.doneColumn() == "" || destCol == nil || archived || indexOfCard(destCol.Cards, c.UUID) < len(destCol.Cards) {
+		return nil
+	}
+	if m.exceedsWIPLimit(destCol, []card.Card{*c}, false) {
+		m.statusMessage += fmt.Sprintf("; '%s' is at its WIP limit", destCol.Title)
+		return nil
+	}
+	m.statusMessage += fmt.Sprintf("; moved to '%s'", destCol.Title)
+	moveErr := m.moveCards([]*card.Card{c}, destCol)
+	err := m.writeBoard()
+	if err == nil {
+		err = moveErr
+	}
+	return err
+}
+
+// cmdCheck toggles checklist item n (1-based) of the previewed card, or the
+// item under the cursor.
+func cmdCheck(m *Model, command, args string) tea.Cmd {
+	i := m.checklistCursor()
+	if arg := strings.TrimSpace(args); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			m.statusMessage = "Usage: :check [item number]"
+			return clearStatusCmd(3 * time.Second)
+		}
+		i = n - 1
+	}
+	return m.toggleChecklistItem(i)
+}
@@ -2,9 +2,11 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,7 +18,13 @@ import (
 
 type commandInfo struct {
 	execute        func(m *Model, command, args string) tea.Cmd
-	getCompletions func(args string) []string
+	getCompletions func(m *Model, args string) []string
+	// modifies marks commands that write the board; they are refused while
+	// the board is read-only.
+	modifies bool
+	// ranges marks commands that apply to the selected or focused cards, and
+	// so to the cards of a range, as in :2,4tag x.
+	ranges bool
 }
 
 var commandRegistry = make(map[string]commandInfo)
@@ -32,71 +40,211 @@ func init() {
 	registerCommand("wQ", commandInfo{execute: cmdQuit})
 	registerCommand("Wq", commandInfo{execute: cmdQuit})
 	registerCommand("WQ", commandInfo{execute: cmdQuit})
+	registerCommand("w", commandInfo{execute: cmdWrite, modifies: true})
+	registerCommand("reload", commandInfo{execute: cmdReload})
 
 	registerCommand("fzf", commandInfo{execute: cmdFzf})
-	registerCommand("new", commandInfo{execute: cmdNew})
+	registerCommand("new", commandInfo{execute: cmdNew, modifies: true})
 	registerCommand("sort", commandInfo{
-		execute: cmdSort,
-		getCompletions: func(args string) []string {
-			return []string{"create", "modify", "name", "size"}
+		execute:  cmdSort,
+		modifies: true,
+		getCompletions: func(m *Model, args string) []string {
+			return []string{"create", "due", "modify", "name", "priority", "size"}
 		},
 	})
-	registerCommand("create", commandInfo{execute: cmdCreateColumn})
-	registerCommand("rename", commandInfo{execute: cmdRenameColumn})
-	registerCommand("delete", commandInfo{execute: cmdDeleteColumn})
-	registerCommand("archive", commandInfo{execute: cmdArchive})
+	registerCommand("create", commandInfo{execute: cmdCreateColumn, modifies: true})
+	registerCommand("rename", commandInfo{execute: cmdRenameColumn, modifies: true})
+	registerCommand("delete", commandInfo{execute: cmdDeleteColumn, modifies: true})
+	registerCommand("archive", commandInfo{execute: cmdArchive, modifies: true, ranges: true})
+	registerCommand("trash", commandInfo{execute: cmdTrash})
+	registerCommand("fsck", commandInfo{execute: cmdFsck})
 	registerCommand("set", commandInfo{
 		execute: cmdSet,
-		getCompletions: func(args string) []string {
-			return []string{"done", "done?"}
+		getCompletions: func(m *Model, args string) []string {
+			return []string{"done", "done?", "internaltrash", "autodone"}
 		},
 	})
 	registerCommand("unset", commandInfo{
 		execute: cmdUnset,
-		getCompletions: func(args string) []string {
-			return []string{"done"}
+		getCompletions: func(m *Model, args string) []string {
+			return []string{"done", "internaltrash", "autodone"}
 		},
 	})
-	registerCommand("done", commandInfo{execute: cmdDone})
+	registerCommand("done", commandInfo{execute: cmdDone, modifies: true, ranges: true})
 	registerCommand("show", commandInfo{
 		execute: cmdShow,
-		getCompletions: func(args string) []string {
+		getCompletions: func(m *Model, args string) []string {
 			return []string{"hidden"}
 		},
 	})
 	registerCommand("hide", commandInfo{
 		execute: cmdHide,
-		getCompletions: func(args string) []string {
+		getCompletions: func(m *Model, args string) []string {
 			return []string{"hidden"}
 		},
 	})
-	registerCommand("right", commandInfo{execute: cmdMoveColumnRight})
-	registerCommand("left", commandInfo{execute: cmdMoveColumnLeft})
+	registerCommand("right", commandInfo{execute: cmdMoveColumnRight, modifies: true})
+	registerCommand("left", commandInfo{execute: cmdMoveColumnLeft, modifies: true})
 	registerCommand("noh", commandInfo{execute: cmdNoHighlight})
 	registerCommand("nohlsearch", commandInfo{execute: cmdNoHighlight})
+	registerCommand("tag", commandInfo{
+		execute:        cmdTag,
+		modifies:       true,
+		ranges:         true,
+		getCompletions: func(m *Model, args string) []string { return m.boardTags() },
+	})
+	registerCommand("untag", commandInfo{
+		execute:        cmdUntag,
+		modifies:       true,
+		ranges:         true,
+		getCompletions: func(m *Model, args string) []string { return m.boardTags() },
+	})
+	registerCommand("due", commandInfo{
+		execute:  cmdDue,
+		modifies: true,
+		ranges:   true,
+		getCompletions: func(m *Model, args string) []string {
+			return []string{"today", "tomorrow", "+1d", "+3d", "+1w"}
+		},
+	})
+	registerCommand("priority", commandInfo{
+		execute:  cmdPriority,
+		modifies: true,
+		ranges:   true,
+		getCompletions: func(m *Model, args string) []string {
+			return append([]string{"up", "down", "none"}, card.Priorities...)
+		},
+	})
+	registerCommand("assign", commandInfo{
+		execute:        cmdAssign,
+		modifies:       true,
+		ranges:         true,
+		getCompletions: func(m *Model, args string) []string { return m.boardValues("assignee") },
+	})
+	registerCommand("lane", commandInfo{
+		execute:        cmdLane,
+		modifies:       true,
+		ranges:         true,
+		getCompletions: func(m *Model, args string) []string { return m.boardValues("lane") },
+	})
+	registerCommand("lanes", commandInfo{
+		execute: cmdLanes,
+		getCompletions: func(m *Model, args string) []string {
+			return append(append([]string(nil), laneFields...), "off")
+		},
+	})
+	registerCommand("check", commandInfo{execute: cmdCheck, modifies: true})
+	registerCommand("preview", commandInfo{
+		execute: cmdPreview,
+		getCompletions: func(m *Model, args string) []string {
+			return append(append([]string(nil), previewDocks...), "off")
+		},
+	})
+	registerCommand("limit", commandInfo{execute: cmdLimit, modifies: true})
+	registerCommand("paste", commandInfo{
+		execute:  cmdPaste,
+		modifies: true,
+		getCompletions: func(m *Model, args string) []string {
+			return []string{"before"}
+		},
+	})
+	registerCommand("move", commandInfo{
+		execute:        cmdMove,
+		modifies:       true,
+		ranges:         true,
+		getCompletions: columnCompletions,
+	})
+	registerCommand("copy", commandInfo{
+		execute:        cmdCopy,
+		modifies:       true,
+		ranges:         true,
+		getCompletions: columnCompletions,
+	})
+	registerCommand("registers", commandInfo{execute: cmdRegisters})
+	registerCommand("reg", commandInfo{execute: cmdRegisters})
+	registerCommand("filter", commandInfo{
+		execute: cmdFilter,
+		getCompletions: func(m *Model, args string) []string {
+			candidates := []string{"due:soon", "due:overdue", "due:any", "due:none"}
+			for _, p := range card.Priorities {
+				candidates = append(candidates, "priority:"+p)
+			}
+			for _, t := range m.boardTags() {
+				candidates = append(candidates, "tag:"+t)
+			}
+			return candidates
+		},
+	})
 }
 
 func cmdQuit(m *Model, command, args string) tea.Cmd {
-	m.history.Drop()
-
 	if len(m.boardStack) > 0 {
 		return m.popBoard()
 	}
 	return tea.Quit
 }
 
+func cmdWrite(m *Model, command, args string) tea.Cmd {
+	var err error
+	if strings.HasSuffix(command, "!") {
+		err = fs.OverwriteBoard(m.board)
+	} else {
+		err = fs.WriteBoard(m.board)
+	}
+	m.unsaved = err != nil
+	if errors.Is(err, fs.ErrBoardChanged) {
+		m.statusMessage = fs.BoardFileName + " changed on disk; use :w! to overwrite it or :reload! to load it"
+		return clearStatusCmd(4 * time.Second)
+	}
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Error writing %s: %v", fs.BoardFileName, err)
+		return clearStatusCmd(4 * time.Second)
+	}
+	m.reloadConflict = false
+	m.statusMessage = "Wrote " + fs.BoardFileName
+	return clearStatusCmd(2 * time.Second)
+}
+
+func cmdReload(m *Model, command, args string) tea.Cmd {
+	if m.unsaved && !strings.HasSuffix(command, "!") {
+		m.statusMessage = "The board has unsaved changes; use :reload! to discard them"
+		return clearStatusCmd(4 * time.Second)
+	}
+	return m.reload()
+}
+
 func cmdFzf(m *Model, command, args string) tea.Cmd {
 	return m.openFZF()
 }
 
 func cmdNew(m *Model, command, args string) tea.Cmd {
-	m.saveStateForUndo()
+	m.saveStateForUndo("create card")
 	title := args
-	currentCol := m.displayColumns[m.focusedColumn]
+	currentCol := m.sourceColumn(m.focusedColumn)
 
 	newCard, err := fs.CreateCard(*currentCol, title)
 	if err != nil {
-		return nil
+		m.history.Drop()
+		return m.writeFailed(err)
+	}
+	var tagErr error
+	created := newCard
+	changed := false
+	if l, ok := m.pasteLane(); ok {
+		changed = setLane(&newCard, m.laneField, l)
+	}
+	if m.filter != nil && len(m.filter.tags) > 0 {
+		// Why: Give the card the filtered tags so it stays visible.
+		for _, t := range m.filter.tags {
+			newCard.AddTag(t)
+		}
+		changed = true
+	}
+	if changed {
+		if err := fs.WriteCard(newCard); err != nil {
+			newCard = created
+			tagErr = err
+		}
 	}
 
 	insertIndex := 0
@@ -104,6 +252,8 @@ func cmdNew(m *Model, command, args string) tea.Cmd {
 	switch m.createCardMode {
 	case "prepend":
 		insertIndex = 0
+	case "append":
+		insertIndex = len(m.displayColumns[m.focusedColumn].Cards)
 	case "before":
 		if currentFocus > 0 {
 			insertIndex = currentFocus - 1
@@ -115,7 +265,8 @@ func cmdNew(m *Model, command, args string) tea.Cmd {
 			insertIndex = 0
 		}
 	}
-	m.createCardMode = "prepend"
+	m.createCardMode = m.defaultCreateMode
+	insertIndex = m.sourceInsertIndex(m.focusedColumn, insertIndex)
 
 	if insertIndex > len(currentCol.Cards) {
 		insertIndex = len(currentCol.Cards)
@@ -123,36 +274,40 @@ func cmdNew(m *Model, command, args string) tea.Cmd {
 
 	currentCol.Cards = append(currentCol.Cards[:insertIndex], append([]card.Card{newCard}, currentCol.Cards[insertIndex:]...)...)
 
-	if err := fs.WriteBoard(m.board); err != nil {
-		return nil
+	err = m.writeBoard()
+	m.updateDisplayColumns()
+	m.focusCard(newCard.UUID)
+	if err == nil {
+		err = tagErr
+	}
+	if err != nil {
+		return m.writeFailed(err)
 	}
-	m.setCurrentFocusedCard(insertIndex + 1)
-	m.ensureFocusedCardIsVisible()
 	return nil
 }
 
 func cmdSort(m *Model, command, args string) tea.Cmd {
-	m.saveStateForUndo()
+	m.saveStateForUndo("sort")
 	descending := strings.HasSuffix(command, "!")
 	field := "name"
 	if args != "" {
 		field = args
 	}
 
-	validFields := map[string]bool{"name": true, "create": true, "modify": true, "size": true}
+	validFields := map[string]bool{"name": true, "create": true, "modify": true, "size": true, "due": true, "priority": true}
 	if !validFields[field] {
 		m.history.Drop()
-		m.statusMessage = fmt.Sprintf("Invalid sort field: %s. Valid: name, create, modify, size.", field)
+		m.statusMessage = fmt.Sprintf("Invalid sort field: %s. Valid: name, create, modify, size, due, priority.", field)
 		return clearStatusCmd(5 * time.Second)
 	}
 
-	currentCol := m.displayColumns[m.focusedColumn]
+	currentCol := m.sourceColumn(m.focusedColumn)
 	if len(currentCol.Cards) < 2 {
 		m.history.Drop()
 		return nil
 	}
 
-	sort.Slice(currentCol.Cards, func(i, j int) bool {
+	sort.SliceStable(currentCol.Cards, func(i, j int) bool {
 		cardI := currentCol.Cards[i]
 		cardJ := currentCol.Cards[j]
 		var less bool
@@ -166,6 +321,11 @@ func cmdSort(m *Model, command, args string) tea.Cmd {
 			less = cardI.Size < cardJ.Size
 		case "name":
 			less = strings.ToLower(cardI.Title) < strings.ToLower(cardJ.Title)
+		case "due":
+			// Cards without a due date sort after every dated card.
+			less = cardI.HasDue() && (!cardJ.HasDue() || cardI.Due < cardJ.Due)
+		case "priority":
+			less = cardI.PriorityRank() < cardJ.PriorityRank()
 		}
 
 		if descending {
@@ -174,14 +334,18 @@ func cmdSort(m *Model, command, args string) tea.Cmd {
 		return less
 	})
 
-	fs.WriteBoard(m.board)
+	err := m.writeBoard()
+	m.updateDisplayColumns()
 	m.setCurrentFocusedCard(0)
 	m.ensureFocusedCardIsVisible()
+	if err != nil {
+		return m.writeFailed(err)
+	}
 	return nil
 }
 
 func cmdCreateColumn(m *Model, command, args string) tea.Cmd {
-	m.saveStateForUndo()
+	m.saveStateForUndo("create column")
 	name := args
 	if name == "" {
 		m.history.Drop()
@@ -195,13 +359,15 @@ func cmdCreateColumn(m *Model, command, args string) tea.Cmd {
 	}
 	m.board.Columns = append(m.board.Columns, newCol)
 	m.updateAndResizeFocus()
-	fs.WriteBoard(m.board)
 	m.focusedColumn = len(m.displayColumns) - 1
+	if err := m.writeBoard(); err != nil {
+		return m.writeFailed(err)
+	}
 	return nil
 }
 
 func cmdRenameColumn(m *Model, command, args string) tea.Cmd {
-	m.saveStateForUndo()
+	m.saveStateForUndo("rename column")
 	newName := strings.TrimSpace(args)
 	if newName == "" {
 		m.history.Drop()
@@ -222,7 +388,7 @@ func cmdRenameColumn(m *Model, command, args string) tea.Cmd {
 		return clearStatusCmd(3 * time.Second)
 	}
 
-	colToRename := m.displayColumns[m.focusedColumn]
+	colToRename := m.sourceColumn(m.focusedColumn)
 	oldName := colToRename.Title
 
 	if oldName == fs.ArchiveColumnName {
@@ -241,20 +407,23 @@ func cmdRenameColumn(m *Model, command, args string) tea.Cmd {
 		m.doneColumnName = newName
 	}
 
-	fs.WriteBoard(m.board)
+	m.updateDisplayColumns()
+	if err := m.writeBoard(); err != nil {
+		return m.writeFailed(err)
+	}
 	m.statusMessage = fmt.Sprintf("Renamed column '%s' to '%s'", oldName, newName)
 	return clearStatusCmd(3 * time.Second)
 }
 
 func cmdDeleteColumn(m *Model, command, args string) tea.Cmd {
-	m.saveStateForUndo()
+	m.saveStateForUndo("delete column")
 	if m.currentFocusedCard() != 0 || len(m.displayColumns) == 0 {
 		m.history.Drop()
 		return nil
 	}
 
 	colToDelete := m.board.Columns[m.focusedColumn]
-	if err := fs.DeleteColumn(colToDelete); err != nil {
+	if err := fs.DeleteColumn(colToDelete, m.internalTrash); err != nil {
 		m.history.Drop()
 		m.statusMessage = fmt.Sprintf("Error deleting column: %v", err)
 		return clearStatusCmd(3 * time.Second)
@@ -273,12 +442,14 @@ func cmdDeleteColumn(m *Model, command, args string) tea.Cmd {
 	}
 
 	m.updateAndResizeFocus()
-	fs.WriteBoard(m.board)
+	if err := m.writeBoard(); err != nil {
+		return m.writeFailed(err)
+	}
 	return nil
 }
 
 func cmdArchive(m *Model, command, args string) tea.Cmd {
-	m.saveStateForUndo()
+	m.saveStateForUndo("archive")
 	if len(m.selected) == 0 {
 		m.history.Drop()
 		return nil
@@ -294,10 +465,15 @@ func cmdArchive(m *Model, command, args string) tea.Cmd {
 
 	cardsToArchive := m.getSelectedOrFocusedCards()
 	movedCards := make([]card.Card, 0, len(cardsToArchive))
+	archived := make(map[string]struct{}, len(cardsToArchive))
+	var moveErr error
 	for _, c := range cardsToArchive {
 		err := fs.MoveCard(c, m.board.Archived)
 		if err == nil {
 			movedCards = append(movedCards, *c)
+			archived[c.UUID] = struct{}{}
+		} else if moveErr == nil {
+			moveErr = err
 		}
 	}
 	m.board.Archived.Cards = append(m.board.Archived.Cards, movedCards...)
@@ -306,18 +482,24 @@ func cmdArchive(m *Model, command, args string) tea.Cmd {
 		col := &m.board.Columns[i]
 		keptCards := col.Cards[:0]
 		for _, c := range col.Cards {
-			if _, isSelected := m.selected[c.UUID]; !isSelected {
+			if _, isArchived := archived[c.UUID]; !isArchived {
 				keptCards = append(keptCards, c)
 			}
 		}
 		col.Cards = keptCards
 	}
 
-	fs.WriteBoard(m.board)
+	err := m.writeBoard()
 
 	m.clearSelection()
 	m.updateAndResizeFocus()
 	m.clampFocusedCard()
+	if err == nil {
+		err = moveErr
+	}
+	if err != nil {
+		return m.writeFailed(err)
+	}
 	return nil
 }
 
@@ -332,19 +514,42 @@ func cmdSet(m *Model, command, args string) tea.Cmd {
 	case "done?":
 		if m.doneColumnName != "" {
 			m.statusMessage = "Done column is: " + m.doneColumnName
+		} else if m.config.DoneColumn != "" {
+			m.statusMessage = "Done column is: " + m.config.DoneColumn + " (from the config)"
 		} else {
 			m.statusMessage = "Done column is not set. Use `:set done`."
 		}
 		return clearStatusCmd(3 * time.Second)
+	case "internaltrash":
+		m.internalTrash = true
+		m.statusMessage = "Deleted cards go to the internal trash"
+		return clearStatusCmd(3 * time.Second)
+	case "autodone":
+		m.autoDone = true
+		m.statusMessage = "Cards move to the done column when their checklist is complete"
+		return clearStatusCmd(3 * time.Second)
 	}
 	return nil
 }
 
 func cmdUnset(m *Model, command, args string) tea.Cmd {
+	if args == "internaltrash" {
+		m.internalTrash = false
+		m.statusMessage = "Deleted cards go to trash-cli when it is installed"
+		return clearStatusCmd(3 * time.Second)
+	}
+	if args == "autodone" {
+		m.autoDone = false
+		m.statusMessage = "Completed checklists no longer move cards"
+		return clearStatusCmd(3 * time.Second)
+	}
 	if args == "done" {
 		if m.doneColumnName != "" {
 			m.doneColumnName = ""
 			m.statusMessage = "Done column has been unset."
+			if m.config.DoneColumn != "" {
+				m.statusMessage = "Done column has been unset; the config's " + m.config.DoneColumn + " applies."
+			}
 		} else {
 			m.statusMessage = "Done column was not set."
 		}
@@ -354,15 +559,15 @@ func cmdUnset(m *Model, command, args string) tea.Cmd {
 }
 
 func cmdDone(m *Model, command, args string) tea.Cmd {
-	m.saveStateForUndo()
-	if m.doneColumnName == "" {
+	m.saveStateForUndo("done")
+	if m.doneColumn() == "" {
 		m.history.Drop()
 		return nil
 	}
 
 	var destCol *column.Column
 	for i := range m.board.Columns {
-		if m.board.Columns[i].Title == m.doneColumnName {
+		if m.board.Columns[i].Title == m.doneColumn() {
 			destCol = &m.board.Columns[i]
 			break
 		}
@@ -378,10 +583,25 @@ func cmdDone(m *Model, command, args string) tea.Cmd {
 		return nil
 	}
 
-	m.moveCards(cardsToMove, destCol)
-	fs.WriteBoard(m.board)
+	incoming := make([]card.Card, len(cardsToMove))
+	for i, c := range cardsToMove {
+		incoming[i] = *c
+	}
+	if !strings.HasSuffix(command, "!") && m.exceedsWIPLimit(destCol, incoming, false) {
+		m.history.Drop()
+		return m.wipLimitMessage(destCol, "done")
+	}
+
+	moveErr := m.moveCards(cardsToMove, destCol)
+	err := m.writeBoard()
 	m.clearSelection()
 	m.clampFocusedCard()
+	if err == nil {
+		err = moveErr
+	}
+	if err != nil {
+		return m.writeFailed(err)
+	}
 	return nil
 }
 
@@ -408,7 +628,7 @@ func cmdHide(m *Model, command, args string) tea.Cmd {
 }
 
 func cmdMoveColumnRight(m *Model, command, args string) tea.Cmd {
-	m.saveStateForUndo()
+	m.saveStateForUndo("move column")
 	if m.focusedColumn >= len(m.board.Columns)-1 {
 		m.history.Drop()
 		return nil
@@ -421,14 +641,17 @@ func cmdMoveColumnRight(m *Model, command, args string) tea.Cmd {
 	i := m.focusedColumn
 	m.board.Columns[i], m.board.Columns[i+1] = m.board.Columns[i+1], m.board.Columns[i]
 	m.focusedColumn++
-	fs.WriteBoard(m.board)
+	err := m.writeBoard()
 	m.updateAndResizeFocus()
+	if err != nil {
+		return m.writeFailed(err)
+	}
 	m.statusMessage = "Moved column right"
 	return clearStatusCmd(2 * time.Second)
 }
 
 func cmdMoveColumnLeft(m *Model, command, args string) tea.Cmd {
-	m.saveStateForUndo()
+	m.saveStateForUndo("move column")
 	if m.focusedColumn <= 0 {
 		m.history.Drop()
 		return nil
@@ -441,8 +664,11 @@ func cmdMoveColumnLeft(m *Model, command, args string) tea.Cmd {
 	i := m.focusedColumn
 	m.board.Columns[i], m.board.Columns[i-1] = m.board.Columns[i-1], m.board.Columns[i]
 	m.focusedColumn--
-	fs.WriteBoard(m.board)
+	err := m.writeBoard()
 	m.updateAndResizeFocus()
+	if err != nil {
+		return m.writeFailed(err)
+	}
 	m.statusMessage = "Moved column left"
 	return clearStatusCmd(2 * time.Second)
 }
@@ -454,3 +680,209 @@ func cmdNoHighlight(m *Model, command, args string) tea.Cmd {
 	m.statusMessage = "Search highlighting cleared"
 	return clearStatusCmd(2 * time.Second)
 }
+
+func cmdTag(m *Model, command, args string) tea.Cmd {
+	tags := strings.Fields(args)
+	if len(tags) == 0 {
+		m.statusMessage = "Usage: :tag {tag}..."
+		return clearStatusCmd(3 * time.Second)
+	}
+	return m.editCards("tags", func(c *card.Card) bool {
+		changed := false
+		for _, t := range tags {
+			if c.AddTag(t) {
+				changed = true
+			}
+		}
+		return changed
+	})
+}
+
+func cmdUntag(m *Model, command, args string) tea.Cmd {
+	tags := strings.Fields(args)
+	return m.editCards("tags", func(c *card.Card) bool {
+		if len(tags) == 0 {
+			changed := len(c.Tags) > 0
+			c.Tags = nil
+			return changed
+		}
+		changed := false
+		for _, t := range tags {
+			if c.RemoveTag(t) {
+				changed = true
+			}
+		}
+		return changed
+	})
+}
+
+// editCards applies edit to the selected or focused cards and writes back
+// every card it changed. what names the edited field in the status message.
+func (m *Model) editCards(what string, edit func(c *card.Card) bool) tea.Cmd {
+	m.saveStateForUndo(what)
+	cards := m.getSelectedOrFocusedCards()
+	if len(cards) == 0 {
+		m.history.Drop()
+		return nil
+	}
+
+	changed := 0
+	var writeErr error
+	for _, c := range cards {
+		if edit(c) {
+			if err := fs.WriteCard(*c); err != nil && writeErr == nil {
+				writeErr = err
+			}
+			changed++
+		}
+	}
+	if changed == 0 {
+		m.history.Drop()
+	}
+
+	m.selected = make(map[string]struct{})
+	m.visualSelectStart = -1
+	if m.mode == visualMode {
+		m.mode = normalMode
+	}
+	m.updateAndResizeFocus()
+	if writeErr != nil {
+		return m.writeFailed(writeErr)
+	}
+	m.statusMessage = fmt.Sprintf("Updated %s on %d card(s)", what, changed)
+	return clearStatusCmd(2 * time.Second)
+}
+
+func cmdDue(m *Model, command, args string) tea.Cmd {
+	due := ""
+	if strings.TrimSpace(args) != "" {
+		var err error
+		due, err = card.ParseDue(args, time.Now())
+		if err != nil {
+			m.statusMessage = err.Error()
+			return clearStatusCmd(3 * time.Second)
+		}
+	}
+	return m.editCards("due date", func(c *card.Card) bool {
+		changed := c.Due != due
+		c.Due = due
+		return changed
+	})
+}
+
+func cmdAssign(m *Model, command, args string) tea.Cmd {
+	assignee := strings.TrimSpace(args)
+	return m.editCards("assignee", func(c *card.Card) bool {
+		changed := c.Assignee != assignee
+		c.Assignee = assignee
+		return changed
+	})
+}
+
+func cmdLane(m *Model, command, args string) tea.Cmd {
+	value := strings.TrimSpace(args)
+	return m.editCards("lane", func(c *card.Card) bool {
+		changed := c.Lane != value
+		c.Lane = value
+		return changed
+	})
+}
+
+func cmdPriority(m *Model, command, args string) tea.Cmd {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "up":
+		return m.bumpPriority(-1)
+	case "down":
+		return m.bumpPriority(1)
+	}
+
+	priority, err := card.ParsePriority(args)
+	if err != nil {
+		m.statusMessage = err.Error()
+		return clearStatusCmd(3 * time.Second)
+	}
+	return m.editCards("priority", func(c *card.Card) bool {
+		changed := c.Priority != priority
+		c.Priority = priority
+		return changed
+	})
+}
+
+// bumpPriority raises (delta < 0) or lowers the priority of the selected or
+// focused cards.
+func (m *Model) bumpPriority(delta int) tea.Cmd {
+	return m.editCards("priority", func(c *card.Card) bool {
+		return c.BumpPriority(delta)
+	})
+}
+
+func cmdLimit(m *Model, command, args string) tea.Cmd {
+	if len(m.displayColumns) == 0 {
+		return nil
+	}
+	limit := 0
+	if strings.TrimSpace(args) != "" {
+		n, err := strconv.Atoi(strings.TrimSpace(args))
+		if err != nil || n < 0 {
+			m.statusMessage = "Usage: :limit {n} (0 removes the limit)"
+			return clearStatusCmd(3 * time.Second)
+		}
+		limit = n
+	}
+
+	col := m.sourceColumn(m.focusedColumn)
+	if col.Title == fs.ArchiveColumnName {
+		m.statusMessage = "Cannot set a WIP limit on the Archived column"
+		return clearStatusCmd(3 * time.Second)
+	}
+
+	m.saveStateForUndo("WIP limit")
+	col.Limit = limit
+	m.updateDisplayColumns()
+	if err := m.writeBoard(); err != nil {
+		return m.writeFailed(err)
+	}
+
+	if limit == 0 {
+		m.statusMessage = fmt.Sprintf("Removed WIP limit from '%s'", col.Title)
+	} else {
+		m.statusMessage = fmt.Sprintf("Set WIP limit of '%s' to %d", col.Title, limit)
+	}
+	return clearStatusCmd(2 * time.Second)
+}
+
+// cmdPaste pastes the unnamed register, or the register named in args, after
+// the focused card, or before it with "before".
+func cmdPaste(m *Model, command, args string) tea.Cmd {
+	before, register := false, ""
+	for _, arg := range strings.Fields(args) {
+		if arg == "before" {
+			before = true
+		} else {
+			register = strings.TrimPrefix(arg, `"`)
+			if register == "" {
+				register = unnamedRegister
+			}
+		}
+	}
+	return m.paste(register, before, strings.HasSuffix(command, "!"), 1)
+}
+
+func cmdFilter(m *Model, command, args string) tea.Cmd {
+	if strings.TrimSpace(args) == "" {
+		m.filter = nil
+		m.updateAndResizeFocus()
+		m.statusMessage = "Filter cleared"
+		return clearStatusCmd(2 * time.Second)
+	}
+
+	f, err := parseFilter(args)
+	if err != nil {
+		m.statusMessage = err.Error()
+		return clearStatusCmd(3 * time.Second)
+	}
+	m.filter = f
+	m.updateAndResizeFocus()
+	m.ensureFocusedCardIsVisible()
+	return nil
+}
@@ -0,0 +1,48 @@
+// internal/tui/config.go
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"kanban/internal/fs"
+)
+
+// applyConfig sets up the key bindings, theme and defaults of cfg. Settings
+// that are wrong are reported and left at their defaults.
+func (m *Model) applyConfig(cfg fs.Config) error {
+	m.config = cfg
+	m.editor = cfg.Editor
+
+	var errs []string
+	m.defaultCreateMode = "prepend"
+	switch cfg.CreateCardMode {
+	case "", "prepend":
+	case "append":
+		m.defaultCreateMode = "append"
+	default:
+		errs = append(errs, fmt.Sprintf("unknown create_card_mode: %s (use prepend or append)", cfg.CreateCardMode))
+	}
+	m.createCardMode = m.defaultCreateMode
+
+	for mode := range cfg.Keys {
+		if mode != "normal" && mode != "visual" {
+			errs = append(errs, fmt.Sprintf("unknown key mode: %s (use normal or visual)", mode))
+		}
+	}
+	var err error
+	if m.normalKeys, err = newKeymap(defaultNormalKeys, normalOperators, cfg.Keys["normal"]); err != nil {
+		errs = append(errs, "normal keys: "+err.Error())
+	}
+	if m.visualKeys, err = newKeymap(defaultVisualKeys, nil, cfg.Keys["visual"]); err != nil {
+		errs = append(errs, "visual keys: "+err.Error())
+	}
+	if err := applyTheme(cfg.Theme, cfg.Themes); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
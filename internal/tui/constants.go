@@ -1,6 +1,14 @@
 package tui
 
+import "time"
+
 const (
 	cardMarginHorizontal    = 0
 	columnPaddingHorizontal = 0
+
+	// dueSoonDays is how many days ahead a due date is highlighted as upcoming.
+	dueSoonDays = 3
+
+	// watchInterval is how often the board's files are polled for external changes.
+	watchInterval = time.Second
 )
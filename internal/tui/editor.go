@@ -18,12 +18,17 @@ type editorFinishedMsg struct {
 	path string
 }
 
-func openEditor(path string) tea.Cmd {
-	editor := os.Getenv("EDITOR")
+// openEditor opens path in editor, or in $EDITOR if editor is "". The
+// editor may be given with arguments, as in "code --wait".
+func openEditor(editor, path string) tea.Cmd {
 	if editor == "" {
-		editor = "vim"
+		editor = os.Getenv("EDITOR")
 	}
-	c := exec.Command(editor, path)
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vim"}
+	}
+	c := exec.Command(args[0], append(args[1:], path)...)
 	return tea.ExecProcess(c, func(err error) tea.Msg {
 		return editorFinishedMsg{err: err, path: path}
 	})
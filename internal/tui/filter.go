@@ -0,0 +1,186 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"kanban/internal/card"
+	"kanban/internal/column"
+)
+
+// cardFilter hides cards that do not match it from displayColumns. All terms
+// must match for a card to be shown.
+type cardFilter struct {
+	query string
+	tags  []string
+	due   string // "", "soon", "overdue", "any" or "none"
+	// priority is nil when unfiltered and may hold "" to match unprioritized cards.
+	priority *string
+}
+
+func parseFilter(query string) (*cardFilter, error) {
+	f := &cardFilter{query: strings.TrimSpace(query)}
+	for _, term := range strings.Fields(query) {
+		key, value, ok := strings.Cut(term, ":")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid filter term: %s", term)
+		}
+		switch key {
+		case "tag":
+			f.tags = append(f.tags, value)
+		case "due":
+			switch value {
+			case "soon", "overdue", "any", "none":
+				f.due = value
+			default:
+				return nil, fmt.Errorf("invalid due filter: %s (use soon, overdue, any or none)", value)
+			}
+		case "priority":
+			p, err := card.ParsePriority(value)
+			if err != nil {
+				return nil, err
+			}
+			f.priority = &p
+		default:
+			return nil, fmt.Errorf("unknown filter field: %s", key)
+		}
+	}
+	return f, nil
+}
+
+func (f *cardFilter) matches(c card.Card) bool {
+	for _, tag := range f.tags {
+		if !c.HasTag(tag) {
+			return false
+		}
+	}
+
+	if f.priority != nil && !strings.EqualFold(c.Priority, *f.priority) {
+		return false
+	}
+
+	if f.due != "" {
+		days, hasDue := c.DueIn(time.Now())
+		switch f.due {
+		case "soon":
+			return hasDue && days <= dueSoonDays
+		case "overdue":
+			return hasDue && days < 0
+		case "any":
+			return hasDue
+		case "none":
+			return !hasDue
+		}
+	}
+	return true
+}
+
+// filterColumn returns a copy of col holding only the cards that match.
+func (f *cardFilter) filterColumn(col column.Column) column.Column {
+	view := col
+	view.Cards = make([]card.Card, 0, len(col.Cards))
+	for _, c := range col.Cards {
+		if f.matches(c) {
+			view.Cards = append(view.Cards, c)
+		}
+	}
+	return view
+}
+
+// sourceColumn returns the board column backing display column i. While a
+// filter or swimlanes are active displayColumns hold filtered or regrouped
+// copies, so anything that mutates a column must go through here.
+func (m *Model) sourceColumn(i int) *column.Column {
+	display := m.displayColumns[i]
+	if m.filteredColumns == nil {
+		return display
+	}
+	if src := m.board.FindColumn(display.Title); src != nil {
+		return src
+	}
+	return display
+}
+
+// sourceInsertIndex maps an insertion index into display column i to the
+// matching index into its source column.
+func (m *Model) sourceInsertIndex(i, displayIdx int) int {
+	if m.filteredColumns == nil {
+		return displayIdx
+	}
+	display := m.displayColumns[i]
+	src := m.sourceColumn(i)
+
+	if displayIdx < len(display.Cards) {
+		return indexOfCard(src.Cards, display.Cards[displayIdx].UUID)
+	}
+	if len(display.Cards) > 0 {
+		return indexOfCard(src.Cards, display.Cards[len(display.Cards)-1].UUID) + 1
+	}
+	return len(src.Cards)
+}
+
+func indexOfCard(cards []card.Card, uuid string) int {
+	for i, c := range cards {
+		if c.UUID == uuid {
+			return i
+		}
+	}
+	return len(cards)
+}
+
+// findCard returns a pointer to the board's copy of the card with uuid.
+func (m *Model) findCard(uuid string) *card.Card {
+	for i := range m.board.Columns {
+		col := &m.board.Columns[i]
+		for j := range col.Cards {
+			if col.Cards[j].UUID == uuid {
+				return &col.Cards[j]
+			}
+		}
+	}
+	for j := range m.board.Archived.Cards {
+		if m.board.Archived.Cards[j].UUID == uuid {
+			return &m.board.Archived.Cards[j]
+		}
+	}
+	return nil
+}
+
+// focusCard moves focus to the displayed card with uuid and reports whether
+// it was found.
+func (m *Model) focusCard(uuid string) bool {
+	for colIdx, col := range m.displayColumns {
+		for cardIdx, c := range col.Cards {
+			if c.UUID == uuid {
+				m.focusedColumn = colIdx
+				m.setCurrentFocusedCard(cardIdx + 1)
+				m.ensureFocusedCardIsVisible()
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// boardTags returns every tag used on the board, in first-seen order.
+func (m *Model) boardTags() []string {
+	seen := make(map[string]struct{})
+	var tags []string
+	collect := func(cards []card.Card) {
+		for _, c := range cards {
+			for _, t := range c.Tags {
+				key := strings.ToLower(t)
+				if _, ok := seen[key]; !ok {
+					seen[key] = struct{}{}
+					tags = append(tags, t)
+				}
+			}
+		}
+	}
+	for _, col := range m.board.Columns {
+		collect(col.Cards)
+	}
+	collect(m.board.Archived.Cards)
+	return tags
+}
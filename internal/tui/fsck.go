@@ -0,0 +1,144 @@
+// internal/tui/fsck.go
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"kanban/internal/fs"
+)
+
+// cmdFsck checks the board for inconsistencies between kanban.md and the
+// card files; :fsck! also fixes what it can.
+func cmdFsck(m *Model, command, args string) tea.Cmd {
+	if strings.HasSuffix(command, "!") {
+		return m.fixBoard()
+	}
+	return m.checkBoard("")
+}
+
+// checkBoard lists the problems in a popup. message is shown in its footer.
+func (m *Model) checkBoard(message string) tea.Cmd {
+	problems, err := m.boardProblems()
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Error checking board: %v", err)
+		return clearStatusCmd(4 * time.Second)
+	}
+	if len(problems) == 0 {
+		m.mode = normalMode
+		m.fsckProblems = nil
+		if message == "" {
+			message = "No problems found"
+		}
+		m.statusMessage = message
+		return clearStatusCmd(3 * time.Second)
+	}
+
+	m.fsckProblems = problems
+	m.fsckCursor = 0
+	m.statusMessage = message
+	m.mode = fsckMode
+	return nil
+}
+
+func (m *Model) fixBoard() tea.Cmd {
+	if m.readOnly {
+		return m.refuseReadOnly()
+	}
+	if m.unsaved {
+		m.statusMessage = "The board has unsaved changes; use :w or :reload! first"
+		return clearStatusCmd(4 * time.Second)
+	}
+
+	problems, err := m.boardProblems()
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Error checking board: %v", err)
+		return clearStatusCmd(4 * time.Second)
+	}
+	fixed, err := fs.FixBoard(problems)
+	if err != nil {
+		m.reload()
+		return m.writeFailed(err)
+	}
+	if fixed > 0 {
+		m.reload()
+	}
+	return m.checkBoard(fmt.Sprintf("Fixed %d of %d problem(s)", fixed, len(problems)))
+}
+
+// boardProblems runs fs.CheckBoard, leaving out the cards deleted in this
+// session: their files stay on disk until exit.
+func (m *Model) boardProblems() ([]fs.Problem, error) {
+	problems, err := fs.CheckBoard()
+	if err != nil {
+		return nil, err
+	}
+	pending := make(map[string]bool, len(m.board.Trash))
+	for _, c := range m.board.Trash {
+		pending[filepath.Clean(c.Path)] = true
+	}
+	kept := problems[:0]
+	for _, p := range problems {
+		if p.Kind == fs.ProblemOrphan && pending[filepath.Clean(p.Path)] {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	return kept, nil
+}
+
+func (m *Model) updateFsckMode(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q", "ctrl+c":
+		m.mode = normalMode
+		m.fsckProblems = nil
+		m.statusMessage = ""
+
+	case "j", "down":
+		if m.fsckCursor < len(m.fsckProblems)-1 {
+			m.fsckCursor++
+		}
+
+	case "k", "up":
+		if m.fsckCursor > 0 {
+			m.fsckCursor--
+		}
+
+	case "f":
+		return m.fixBoard()
+	}
+	return nil
+}
+
+func renderFsck(m *Model) string {
+	fixable := 0
+	lines := make([]string, len(m.fsckProblems))
+	for i, p := range m.fsckProblems {
+		mark := " "
+		if p.Fixable {
+			mark = "*"
+			fixable++
+		}
+		lines[i] = fmt.Sprintf("%s %-14s %s", mark, p.Kind, p.Path)
+	}
+
+	var footer []string
+	if len(m.fsckProblems) > 0 {
+		footer = append(footer, m.fsckProblems[m.fsckCursor].Detail)
+	}
+	if m.statusMessage != "" {
+		footer = append(footer, m.statusMessage)
+	}
+	footer = append(footer, popupHintStyle.Render("*: fixable · f: fix · esc: close"))
+
+	title := fmt.Sprintf("Board check: %d problem(s), %d fixable", len(m.fsckProblems), fixable)
+	return renderListPopup(m, title, lines, m.fsckCursor, "No problems found", strings.Join(footer, "\n"))
+}
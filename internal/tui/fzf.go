@@ -57,6 +57,9 @@ type FZFModel struct {
 	width       int
 	height      int
 	ready       bool
+	// previewWidth is the width card bodies are wrapped to beside the
+	// results, or 0 for no preview.
+	previewWidth int
 }
 
 func NewFZFModel() FZFModel {
@@ -87,6 +90,12 @@ func (m *FZFModel) SetSize(w, h int) {
 	m.viewport.Height = popupHeight - 2 // Title and prompt
 }
 
+// SetPreviewWidth sets the width the selected card's body is wrapped to, that
+// of a card in the focused column.
+func (m *FZFModel) SetPreviewWidth(w int) {
+	m.previewWidth = w
+}
+
 func (m *FZFModel) SetItems(items []FzfItem) {
 	m.items = items
 	m.filter()
@@ -201,6 +210,20 @@ func (m FZFModel) renderResults() string {
 	return b.String()
 }
 
+// previewLines renders the title and body of the selected card.
+func (m FZFModel) previewLines(width int) []string {
+	if len(m.matches) == 0 || m.selectedIndex >= len(m.matches) {
+		return nil
+	}
+	c := m.items[m.matches[m.selectedIndex].Index].Card
+	lines := wrapMarkdown(previewTitleStyle.Render(c.Title), width)
+	if c.HasContent() {
+		lines = append(lines, "")
+		lines = append(lines, renderMarkdown(c.Content, width)...)
+	}
+	return lines
+}
+
 func (m FZFModel) View() string {
 	if !m.ready {
 		return ""
@@ -215,12 +238,25 @@ func (m FZFModel) View() string {
 	m.viewport.Width = popupWidth - 4
 	m.viewport.Height = popupHeight - 3
 
-	m.viewport.SetContent(m.renderResults())
+	rendered := m.renderResults()
+	m.viewport.SetContent(rendered)
 
 	title := "Find Card"
 	prompt := m.textinput.View()
 
-	content := lipgloss.JoinVertical(lipgloss.Left, title, m.viewport.View(), prompt)
+	results := m.viewport.View()
+	// Why: Show the selected card's body beside the results, wrapped like
+	// the cards on the board, unless that cuts off results narrower than it.
+	previewWidth := m.previewWidth
+	listWidth := m.viewport.Width - previewWidth - 3
+	if previewWidth > 0 && listWidth >= min(lipgloss.Width(rendered), previewWidth) {
+		list := lipgloss.NewStyle().Width(listWidth).Render(lipgloss.NewStyle().MaxWidth(listWidth).Render(results))
+		rule := previewRuleStyle.Render(strings.TrimSuffix(strings.Repeat("│\n", m.viewport.Height), "\n"))
+		preview := lipgloss.NewStyle().MaxHeight(m.viewport.Height).Render(strings.Join(m.previewLines(previewWidth), "\n"))
+		results = lipgloss.JoinHorizontal(lipgloss.Top, list, " ", rule, " ", preview)
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, title, results, prompt)
 	popup := fzfPopupStyle.Width(popupWidth).Height(popupHeight).Render(content)
 
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, popup)
@@ -0,0 +1,153 @@
+// internal/tui/jumps.go
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"kanban/internal/fs"
+)
+
+// maxJumps caps the jumplist; the oldest jumps are dropped first.
+const maxJumps = 100
+
+// position returns where focus is: the focused card, or the column header.
+func (m *Model) position() fs.Position {
+	if len(m.displayColumns) == 0 {
+		return fs.Position{}
+	}
+	col := m.displayColumns[m.focusedColumn]
+	p := fs.Position{Column: col.Title}
+	if focus := m.currentFocusedCard(); focus > 0 && focus <= len(col.Cards) {
+		p.Card = col.Cards[focus-1].UUID
+	}
+	return p
+}
+
+// goToPosition focuses p and reports whether it is shown. Cards are found by
+// UUID wherever they were moved to.
+func (m *Model) goToPosition(p fs.Position) bool {
+	if p.Card != "" {
+		return m.focusCard(p.Card)
+	}
+	for i, col := range m.displayColumns {
+		if col.Title == p.Column {
+			m.focusedColumn = i
+			m.setCurrentFocusedCard(0)
+			m.ensureFocusedCardIsVisible()
+			return true
+		}
+	}
+	return false
+}
+
+// samePosition reports whether a and b are the same card, wherever it was, or
+// the same column header.
+func samePosition(a, b fs.Position) bool {
+	if a.Card != "" || b.Card != "" {
+		return a.Card == b.Card
+	}
+	return a.Column == b.Column
+}
+
+// pushJump adds p, a position focus jumped away from, to the end of the
+// jumplist, dropping older entries for the same place.
+func (m *Model) pushJump(p fs.Position) {
+	jumps := make([]fs.Position, 0, len(m.jumps)+1)
+	for _, j := range m.jumps {
+		if !samePosition(j, p) {
+			jumps = append(jumps, j)
+		}
+	}
+	jumps = append(jumps, p)
+	if len(jumps) > maxJumps {
+		jumps = jumps[len(jumps)-maxJumps:]
+	}
+	m.jumps = jumps
+	m.jumpIndex = len(m.jumps)
+}
+
+// recordJump adds from to the jumplist if focus has moved away from it.
+func (m *Model) recordJump(from fs.Position) {
+	if from != (fs.Position{}) && !samePosition(from, m.position()) {
+		m.pushJump(from)
+	}
+}
+
+// jumpBack goes count entries back in the jumplist (C-o), skipping places
+// that are no longer shown.
+func (m *Model) jumpBack(count int) tea.Cmd {
+	if m.jumpIndex >= len(m.jumps) {
+		// Why: Remember where the jumps started, so that C-i returns here.
+		m.pushJump(m.position())
+		m.jumpIndex = len(m.jumps) - 1
+	}
+	for i := m.jumpIndex - count; i >= 0; i-- {
+		if m.goToPosition(m.jumps[i]) {
+			m.jumpIndex = i
+			return nil
+		}
+	}
+	m.statusMessage = "At the start of the jumplist"
+	return clearStatusCmd(2 * time.Second)
+}
+
+// jumpForward goes count entries forward in the jumplist (C-i).
+func (m *Model) jumpForward(count int) tea.Cmd {
+	for i := m.jumpIndex + count; i < len(m.jumps); i++ {
+		if m.goToPosition(m.jumps[i]) {
+			m.jumpIndex = i
+			return nil
+		}
+	}
+	m.statusMessage = "At the end of the jumplist"
+	return clearStatusCmd(2 * time.Second)
+}
+
+func isMarkName(name string) bool {
+	return len(name) == 1 && (name[0] >= 'a' && name[0] <= 'z' || name[0] >= 'A' && name[0] <= 'Z')
+}
+
+// setMark remembers the focused card, or column header, as mark name.
+func (m *Model) setMark(name string) tea.Cmd {
+	if !isMarkName(name) {
+		m.statusMessage = fmt.Sprintf("Invalid mark: %s (use a letter)", name)
+		return clearStatusCmd(2 * time.Second)
+	}
+	if m.marks == nil {
+		m.marks = make(map[string]fs.Position)
+	}
+	m.marks[name] = m.position()
+	return nil
+}
+
+// goToMark focuses mark name. The marks ' and ` go back to where the last
+// jump started.
+func (m *Model) goToMark(name string) tea.Cmd {
+	from := m.position()
+	if name == "'" || name == "`" {
+		for i := len(m.jumps) - 1; i >= 0; i-- {
+			if !samePosition(m.jumps[i], from) && m.goToPosition(m.jumps[i]) {
+				m.recordJump(from)
+				return nil
+			}
+		}
+		m.statusMessage = "No previous position"
+		return clearStatusCmd(2 * time.Second)
+	}
+
+	p, ok := m.marks[name]
+	switch {
+	case !ok:
+		m.statusMessage = fmt.Sprintf("Mark not set: %s", name)
+	case m.goToPosition(p):
+		m.recordJump(from)
+		return nil
+	case p.Card != "" && m.findCard(p.Card) != nil:
+		m.statusMessage = fmt.Sprintf("Card of mark %s is hidden", name)
+	default:
+		m.statusMessage = fmt.Sprintf("Card of mark %s no longer exists", name)
+	}
+	return clearStatusCmd(2 * time.Second)
+}
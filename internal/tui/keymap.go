@@ -0,0 +1,314 @@
+// internal/tui/keymap.go
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"kanban/internal/fs"
+)
+
+// maxCount caps the count typed before a command.
+const maxCount = 9999
+
+// defaultNormalKeys binds the actions of normal mode to their keys. Keys
+// separated by a space form a sequence.
+var defaultNormalKeys = map[string][]string{
+	"quit":              {"ctrl+c", "Z Z"},
+	"clear":             {"esc"},
+	"command":           {":"},
+	"search":            {"/"},
+	"search-backward":   {"?"},
+	"next-match":        {"n"},
+	"prev-match":        {"N"},
+	"find":              {"ctrl+p"},
+	"left":              {"h", "left"},
+	"right":             {"l", "right"},
+	"up":                {"k", "up"},
+	"down":              {"j", "down"},
+	"lane-down":         {"J"},
+	"lane-up":           {"K"},
+	"first":             {"g g"},
+	"last":              {"G"},
+	"block-end":         {"}"},
+	"block-start":       {"{"},
+	"follow-link":       {"g f"},
+	"edit":              {"enter"},
+	"new-after":         {"o"},
+	"new-before":        {"O"},
+	"visual":            {"v", "V"},
+	"yank":              {"y"},
+	"cut":               {"d"},
+	"paste":             {"p"},
+	"paste-before":      {"P"},
+	"delete":            {"delete", "backspace"},
+	"priority-up":       {"+"},
+	"priority-down":     {"-"},
+	"undo":              {"u"},
+	"redo":              {"ctrl+r"},
+	"repeat":            {"."},
+	"preview":           {"i"},
+	"preview-half-down": {"ctrl+d"},
+	"preview-half-up":   {"ctrl+u"},
+	"preview-down":      {"ctrl+e"},
+	"preview-up":        {"ctrl+y"},
+	"checklist-next":    {"]"},
+	"checklist-prev":    {"["},
+	"checklist-toggle":  {"space"},
+	"set-mark":          {"m"},
+	"goto-mark":         {"'", "`"},
+	"jump-back":         {"ctrl+o"},
+	"jump-forward":      {"tab"},
+	"register":          {"\""},
+	"record-macro":      {"q"},
+	"play-macro":        {"@"},
+}
+
+// defaultVisualKeys binds the actions of visual mode to their keys.
+var defaultVisualKeys = map[string][]string{
+	"quit":          {"ctrl+c"},
+	"exit":          {"esc", "v", "V"},
+	"command":       {":"},
+	"left":          {"h", "left"},
+	"right":         {"l", "right"},
+	"up":            {"k", "up"},
+	"down":          {"j", "down"},
+	"first":         {"g g"},
+	"last":          {"G"},
+	"block-end":     {"}"},
+	"block-start":   {"{"},
+	"yank":          {"y"},
+	"cut":           {"d"},
+	"delete":        {"delete", "backspace"},
+	"priority-up":   {"+"},
+	"priority-down": {"-"},
+	"register":      {"\""},
+	"record-macro":  {"q"},
+	"play-macro":    {"@"},
+}
+
+// normalOperators are the actions of normal mode that wait for a motion and
+// apply to the cards it moves over. Typed twice, as in "dd", they apply to the
+// focused card and the count-1 cards below it.
+var normalOperators = []string{"yank", "cut"}
+
+// argumentActions take the key typed after them as their argument, as "m a"
+// sets mark a. The register action instead names the register of the command
+// typed after it, as in "ayy.
+var argumentActions = map[string]bool{
+	"set-mark": true, "goto-mark": true, "register": true,
+	"record-macro": true, "play-macro": true,
+}
+
+// motions are the actions that can follow an operator.
+var motions = map[string]bool{
+	"up": true, "down": true, "first": true, "last": true,
+	"block-end": true, "block-start": true,
+}
+
+// keymap resolves keys to the actions of one mode.
+type keymap struct {
+	actions map[string]string
+	// prefixes are the keys that start longer sequences.
+	prefixes  map[string]bool
+	operators map[string]bool
+}
+
+// newKeymap binds the actions in defaults, with the bindings of the config
+// replacing those of the same actions. An action given no keys is unbound.
+func newKeymap(defaults map[string][]string, operators []string, config map[string]fs.KeyList) (keymap, error) {
+	km := keymap{actions: make(map[string]string), prefixes: make(map[string]bool), operators: make(map[string]bool)}
+	for _, op := range operators {
+		km.operators[op] = true
+	}
+	bind := func(action string, keys []string) {
+		for _, k := range keys {
+			seq := strings.Fields(k)
+			for i := range seq {
+				seq[i] = keyName(seq[i])
+			}
+			if len(seq) == 0 {
+				// Why: The space key may be written as " ".
+				seq = []string{" "}
+			}
+			km.actions[strings.Join(seq, "\x00")] = action
+			for i := 1; i < len(seq); i++ {
+				km.prefixes[strings.Join(seq[:i], "\x00")] = true
+			}
+		}
+	}
+
+	var unknown []string
+	for action, keys := range defaults {
+		if _, ok := config[action]; !ok {
+			bind(action, keys)
+		}
+	}
+	actions := make([]string, 0, len(config))
+	for action := range config {
+		actions = append(actions, action)
+	}
+	// Why: Sorted, so that a key given to two actions always ends up with
+	// the same one.
+	sort.Strings(actions)
+	for _, action := range actions {
+		if _, ok := defaults[action]; !ok {
+			unknown = append(unknown, action)
+			continue
+		}
+		bind(action, config[action])
+	}
+	if len(unknown) > 0 {
+		return km, fmt.Errorf("unknown action(s): %s", strings.Join(unknown, ", "))
+	}
+	return km, nil
+}
+
+// keyName converts the name of a key in the config file to the name
+// bubbletea gives it.
+func keyName(k string) string {
+	switch strings.ToLower(k) {
+	case "space":
+		return " "
+	case "escape":
+		return "esc"
+	case "return":
+		return "enter"
+	case "ctrl+i":
+		// Why: Terminals send C-i as tab.
+		return "tab"
+	}
+	return k
+}
+
+// keyInput is a complete command typed in normal or visual mode.
+type keyInput struct {
+	action string
+	// count is the count typed with the command, 0 if none.
+	count int
+	// motion is the motion that followed an operator, or "" if the
+	// operator was repeated.
+	motion string
+	// arg is the key typed after an action in argumentActions.
+	arg string
+	// register is the register named before the command, or "".
+	register string
+}
+
+// keyParser collects the keys of a command: a count, the keys of a sequence
+// such as "g g" and, after an operator, another count and a motion, as in
+// "2d3j".
+type keyParser struct {
+	count    int
+	keys     []string
+	operator string
+	// opKeys are the keys of the operator, which typed again complete it.
+	opKeys    string
+	opCount   int
+	argAction string
+	register  string
+	// regCount is the count typed before the register, as in 2"ayy.
+	regCount int
+	typed    []string
+}
+
+// feed adds key to the command being typed. It returns the command and true
+// once it is complete; the action is "" if the keys were cancelled with esc
+// or do not form a command.
+func (p *keyParser) feed(km keymap, key string) (keyInput, bool) {
+	p.typed = append(p.typed, key)
+	if key == "esc" && len(p.typed) > 1 {
+		p.reset()
+		return keyInput{}, true
+	}
+	if p.argAction == "register" {
+		p.argAction, p.register = "", key
+		p.regCount, p.count = p.count, 0
+		return keyInput{}, false
+	}
+	if p.argAction != "" {
+		return p.complete(keyInput{action: p.argAction, count: p.count, arg: key}), true
+	}
+
+	if d, err := strconv.Atoi(key); err == nil && len(key) == 1 && len(p.keys) == 0 {
+		count := &p.count
+		if p.operator != "" {
+			count = &p.opCount
+		}
+		if d > 0 || *count > 0 {
+			*count = *count*10 + d
+			if *count > maxCount {
+				*count = maxCount
+			}
+			return keyInput{}, false
+		}
+	}
+
+	p.keys = append(p.keys, key)
+	seq := strings.Join(p.keys, "\x00")
+	action, bound := km.actions[seq]
+	if !bound {
+		if km.prefixes[seq] {
+			return keyInput{}, false
+		}
+		p.reset()
+		return keyInput{}, true
+	}
+	p.keys = nil
+
+	if p.operator != "" {
+		input := keyInput{action: p.operator, count: p.count}
+		if p.opCount > 0 {
+			input.count = max(p.count, 1) * p.opCount
+		}
+		switch {
+		case seq == p.opKeys:
+		case motions[action]:
+			input.motion = action
+		default:
+			input.action = ""
+		}
+		return p.complete(input), true
+	}
+	if km.operators[action] {
+		p.operator = action
+		p.opKeys = seq
+		return keyInput{}, false
+	}
+	if argumentActions[action] {
+		p.argAction = action
+		return keyInput{}, false
+	}
+	return p.complete(keyInput{action: action, count: p.count}), true
+}
+
+// complete adds the register and its count to input and resets the parser.
+func (p *keyParser) complete(input keyInput) keyInput {
+	input.register = p.register
+	if p.regCount > 0 {
+		input.count = max(input.count, 1) * p.regCount
+	}
+	p.reset()
+	return input
+}
+
+func (p *keyParser) reset() {
+	*p = keyParser{}
+}
+
+// pending returns the keys of the command typed so far, or "".
+func (p *keyParser) pending() string {
+	var b strings.Builder
+	for _, k := range p.typed {
+		if k == " " {
+			k = "space"
+		}
+		if len(k) > 1 {
+			k = "<" + k + ">"
+		}
+		b.WriteString(k)
+	}
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"kanban/internal/fs"
+)
+
+func TestKeyParserFeed(t *testing.T) {
+	km, err := newKeymap(defaultNormalKeys, normalOperators, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		keys string // Separated by spaces
+		want keyInput
+	}{
+		{"j", keyInput{action: "down"}},
+		{"3 j", keyInput{action: "down", count: 3}},
+		{"1 0 j", keyInput{action: "down", count: 10}},
+		{"9 9 9 9 9 j", keyInput{action: "down", count: maxCount}},
+		{"0", keyInput{}},
+		{"g g", keyInput{action: "first"}},
+		{"5 g g", keyInput{action: "first", count: 5}},
+		{"g f", keyInput{action: "follow-link"}},
+		{"g x", keyInput{}},
+		{"Z Z", keyInput{action: "quit"}},
+		{"d d", keyInput{action: "cut"}},
+		{"d j", keyInput{action: "cut", motion: "down"}},
+		{"2 d 3 j", keyInput{action: "cut", count: 6, motion: "down"}},
+		{"d 3 j", keyInput{action: "cut", count: 3, motion: "down"}},
+		{"y g g", keyInput{action: "yank", motion: "first"}},
+		{"d p", keyInput{}},
+		{"\" a 3 y y", keyInput{action: "yank", count: 3, register: "a"}},
+		{"2 \" a 3 y y", keyInput{action: "yank", count: 6, register: "a"}},
+		{"\" a p", keyInput{action: "paste", register: "a"}},
+		{"m a", keyInput{action: "set-mark", arg: "a"}},
+		{"2 @ q", keyInput{action: "play-macro", count: 2, arg: "q"}},
+		{"esc", keyInput{action: "clear"}},
+		{"3 esc", keyInput{}},
+		{"d esc", keyInput{}},
+		{"g esc", keyInput{}},
+		{"\" esc", keyInput{}},
+		{"2 d 3 esc", keyInput{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keys, func(t *testing.T) {
+			var p keyParser
+			keys := strings.Fields(tt.keys)
+			for i, key := range keys {
+				got, done := p.feed(km, key)
+				if i < len(keys)-1 {
+					if done {
+						t.Fatalf("feed(%q) completed %+v before the last key", key, got)
+					}
+					continue
+				}
+				if !done {
+					t.Fatalf("feed(%q) did not complete; pending %q", key, p.pending())
+				}
+				if got != tt.want {
+					t.Errorf("feed() = %+v; want %+v", got, tt.want)
+				}
+			}
+			if pending := p.pending(); pending != "" {
+				t.Errorf("pending() = %q after the command; want \"\"", pending)
+			}
+		})
+	}
+}
+
+func TestKeyParserPending(t *testing.T) {
+	km, err := newKeymap(defaultNormalKeys, normalOperators, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p keyParser
+	for _, key := range []string{"2", "d", "3", "g"} {
+		p.feed(km, key)
+	}
+	if got, want := p.pending(), "2d3g"; got != want {
+		t.Errorf("pending() = %q; want %q", got, want)
+	}
+}
+
+func TestKeymapConfig(t *testing.T) {
+	km, err := newKeymap(defaultNormalKeys, normalOperators, map[string]fs.KeyList{"first": {"H"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p keyParser
+	if got, _ := p.feed(km, "H"); got.action != "first" {
+		t.Errorf("feed(H) = %+v; want first", got)
+	}
+	// Why: Rebinding first frees "g g", but "g f" still makes g a prefix.
+	p.feed(km, "g")
+	if got, done := p.feed(km, "g"); !done || got.action != "" {
+		t.Errorf("feed(g g) = %+v, %v; want no action", got, done)
+	}
+}
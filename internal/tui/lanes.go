@@ -0,0 +1,507 @@
+// internal/tui/lanes.go
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"kanban/internal/card"
+	"kanban/internal/fs"
+)
+
+// laneFields are the card fields swimlanes can group by.
+var laneFields = []string{"tag", "assignee", "priority", "lane"}
+
+var (
+	laneLabelStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("250")).
+			Background(lipgloss.Color("236")).
+			Bold(true).
+			Padding(0, 1)
+
+	focusedLaneLabelStyle = laneLabelStyle.Copy().
+				Foreground(lipgloss.Color("231")).
+				Background(lipgloss.Color("98"))
+
+	laneSeparatorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+)
+
+// lane is one swimlane: the cards whose lane field holds value, compared
+// case-insensitively. The lane of cards without a value has value "".
+type lane struct {
+	key   string
+	value string
+}
+
+// laneBand is a lane as laid out on screen.
+type laneBand struct {
+	lane   int
+	height int
+	// clipped is set when the band is shorter than its cards.
+	clipped bool
+}
+
+func isLaneField(field string) bool {
+	for _, f := range laneFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// validLaneField drops a lane field saved by a newer version.
+func validLaneField(field string) string {
+	if isLaneField(field) {
+		return field
+	}
+	return ""
+}
+
+// laneValue returns the value of field that places c in a lane. Cards are
+// grouped by their first tag.
+func laneValue(c card.Card, field string) string {
+	switch field {
+	case "tag":
+		if len(c.Tags) > 0 {
+			return c.Tags[0]
+		}
+	case "assignee":
+		return c.Assignee
+	case "priority":
+		return c.Priority
+	case "lane":
+		return c.Lane
+	}
+	return ""
+}
+
+func laneKey(c card.Card, field string) string {
+	return strings.ToLower(strings.TrimSpace(laneValue(c, field)))
+}
+
+// laneTitle is the label drawn above the lane.
+func laneTitle(l lane, field string) string {
+	if l.value != "" {
+		return l.value
+	}
+	switch field {
+	case "tag":
+		return "no tag"
+	case "assignee":
+		return "unassigned"
+	case "priority":
+		return "no priority"
+	}
+	return "no lane"
+}
+
+// setLane moves c into lane l and reports whether c changed. Moving a card
+// to another tag lane replaces its first tag.
+func setLane(c *card.Card, field string, l lane) bool {
+	if laneKey(*c, field) == l.key {
+		return false
+	}
+	switch field {
+	case "tag":
+		if l.value == "" {
+			c.Tags = nil
+			break
+		}
+		tags := []string{l.value}
+		for i, t := range c.Tags {
+			if i > 0 && !strings.EqualFold(t, l.value) {
+				tags = append(tags, t)
+			}
+		}
+		c.Tags = tags
+	case "assignee":
+		c.Assignee = l.value
+	case "priority":
+		c.Priority = l.value
+	case "lane":
+		c.Lane = l.value
+	}
+	return true
+}
+
+// groupByLane collects the lanes of the displayed cards and orders each
+// display column by lane, keeping the board order within a lane. Lanes are
+// sorted by value, priorities thus from P0 down, with cards lacking a value
+// last. Only lanes holding cards are shown.
+func (m *Model) groupByLane() {
+	values := make(map[string]string)
+	for _, col := range m.displayColumns {
+		for _, c := range col.Cards {
+			key := laneKey(c, m.laneField)
+			if _, ok := values[key]; !ok {
+				values[key] = strings.TrimSpace(laneValue(c, m.laneField))
+			}
+		}
+	}
+
+	m.lanes = make([]lane, 0, len(values))
+	for key, value := range values {
+		if key != "" {
+			m.lanes = append(m.lanes, lane{key: key, value: value})
+		}
+	}
+	sort.Slice(m.lanes, func(i, j int) bool { return m.lanes[i].key < m.lanes[j].key })
+	if _, ok := values[""]; ok {
+		m.lanes = append(m.lanes, lane{})
+	}
+
+	rank := make(map[string]int, len(m.lanes))
+	for i, l := range m.lanes {
+		rank[l.key] = i
+	}
+	for _, col := range m.displayColumns {
+		sort.SliceStable(col.Cards, func(i, j int) bool {
+			return rank[laneKey(col.Cards[i], m.laneField)] < rank[laneKey(col.Cards[j], m.laneField)]
+		})
+	}
+}
+
+// laneOf returns the index of the lane holding c.
+func (m *Model) laneOf(c card.Card) int {
+	key := laneKey(c, m.laneField)
+	for i, l := range m.lanes {
+		if l.key == key {
+			return i
+		}
+	}
+	return len(m.lanes)
+}
+
+// currentLane is the lane of the focused card, or the lane last visited
+// while a column header or an empty cell is focused.
+func (m *Model) currentLane() int {
+	if len(m.lanes) == 0 || len(m.displayColumns) == 0 {
+		return 0
+	}
+	if focus := m.currentFocusedCard(); focus > 0 && focus <= len(m.displayColumns[m.focusedColumn].Cards) {
+		return m.laneOf(m.displayColumns[m.focusedColumn].Cards[focus-1])
+	}
+	if m.focusedLane >= len(m.lanes) {
+		return len(m.lanes) - 1
+	}
+	return m.focusedLane
+}
+
+// focusLane focuses the first card of lane l in the focused column, or the
+// empty cell if the column has none.
+func (m *Model) focusLane(l int) {
+	m.focusedLane = l
+	m.setCurrentFocusedCard(0)
+	for i, c := range m.displayColumns[m.focusedColumn].Cards {
+		if m.laneOf(c) == l {
+			m.setCurrentFocusedCard(i + 1)
+			break
+		}
+	}
+	m.ensureFocusedCardIsVisible()
+}
+
+// keepLane refocuses the focused column in lane l after moving sideways.
+func (m *Model) keepLane(l int) {
+	if m.laneField == "" || len(m.displayColumns) == 0 || m.currentLane() == l && m.currentFocusedCard() > 0 {
+		return
+	}
+	m.focusLane(l)
+}
+
+func (m *Model) moveLane(delta int) tea.Cmd {
+	if m.laneField == "" {
+		m.statusMessage = "No swimlanes; use :lanes " + strings.Join(laneFields, "|")
+		return clearStatusCmd(2 * time.Second)
+	}
+	l := m.currentLane() + delta
+	if l >= 0 && l < len(m.lanes) {
+		m.focusLane(l)
+	}
+	return nil
+}
+
+// pasteLane returns the lane pasted cards go to, if swimlanes are shown.
+func (m *Model) pasteLane() (lane, bool) {
+	if m.laneField == "" || len(m.lanes) == 0 {
+		return lane{}, false
+	}
+	return m.lanes[m.currentLane()], true
+}
+
+// laneInsertIndex returns where cards pasted into the focused cell go in the
+// source column: next to the focused card, or ahead of the lane's cards.
+func (m *Model) laneInsertIndex(before bool) int {
+	display := m.displayColumns[m.focusedColumn]
+	src := m.sourceColumn(m.focusedColumn)
+	if focus := m.currentFocusedCard(); focus > 0 {
+		idx := indexOfCard(src.Cards, display.Cards[focus-1].UUID)
+		if !before && idx < len(src.Cards) {
+			idx++
+		}
+		return idx
+	}
+	l := m.currentLane()
+	for i, c := range display.Cards {
+		if m.laneOf(c) >= l {
+			return m.sourceInsertIndex(m.focusedColumn, i)
+		}
+	}
+	return m.sourceInsertIndex(m.focusedColumn, len(display.Cards))
+}
+
+// assignLane moves c into lane l and saves it if that changed it.
+func (m *Model) assignLane(c *card.Card, l lane) error {
+	if !setLane(c, m.laneField, l) {
+		return nil
+	}
+	return fs.WriteCard(*c)
+}
+
+// boardValues returns the values of field used on the board, in first-seen
+// order, for completion.
+func (m *Model) boardValues(field string) []string {
+	seen := make(map[string]struct{})
+	var values []string
+	collect := func(cards []card.Card) {
+		for _, c := range cards {
+			value := strings.TrimSpace(laneValue(c, field))
+			key := strings.ToLower(value)
+			if _, ok := seen[key]; !ok && value != "" {
+				seen[key] = struct{}{}
+				values = append(values, value)
+			}
+		}
+	}
+	for _, col := range m.board.Columns {
+		collect(col.Cards)
+	}
+	collect(m.board.Archived.Cards)
+	return values
+}
+
+func cmdLanes(m *Model, command, args string) tea.Cmd {
+	field := strings.ToLower(strings.TrimSpace(args))
+	if field == "" || field == "off" {
+		m.laneField = ""
+		m.updateAndResizeFocus()
+		m.ensureFocusedCardIsVisible()
+		m.statusMessage = "Swimlanes off"
+		return clearStatusCmd(2 * time.Second)
+	}
+	if !isLaneField(field) {
+		m.statusMessage = fmt.Sprintf("Unknown lane field: %s (use %s or off)", field, strings.Join(laneFields, ", "))
+		return clearStatusCmd(3 * time.Second)
+	}
+
+	m.laneField = field
+	m.laneOffset = 0
+	m.updateAndResizeFocus()
+	m.focusedLane = m.currentLane()
+	m.ensureFocusedCardIsVisible()
+	m.statusMessage = "Swimlanes by " + field
+	return clearStatusCmd(2 * time.Second)
+}
+
+// laneColumnWidths splits the window width between the display columns the
+// way renderBoard does.
+func laneColumnWidths(m *Model) []int {
+	numColumns := len(m.displayColumns)
+	availableWidth := m.boardWidth() - (numColumns - 1)
+	widths := make([]int, numColumns)
+	for i := range widths {
+		widths[i] = availableWidth / numColumns
+		if i < availableWidth%numColumns {
+			widths[i]++
+		}
+	}
+	return widths
+}
+
+// joinLaneCells joins cells of the given height with column separators.
+func joinLaneCells(cells []string, height int) string {
+	separator := laneSeparatorStyle.Render(strings.TrimSuffix(strings.Repeat("│\n", height), "\n"))
+	var parts []string
+	for i, cell := range cells {
+		parts = append(parts, cell)
+		if i < len(cells)-1 {
+			parts = append(parts, separator)
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, parts...)
+}
+
+func renderLaneHeaders(m *Model, widths []int) string {
+	var headers []string
+	height := 1
+	for i := range m.displayColumns {
+		focused := m.focusedColumn == i && m.currentFocusedCard() == 0
+		header, headerStyle := columnHeader(m, i, focused)
+		headerContentWidth := widths[i] - columnStyle.GetHorizontalPadding() - headerStyle.GetHorizontalPadding()
+		rendered := columnStyle.Copy().Width(widths[i]).Render(headerStyle.Copy().Width(headerContentWidth).Render(header))
+		headers = append(headers, rendered)
+		if h := lipgloss.Height(rendered); h > height {
+			height = h
+		}
+	}
+	return joinLaneCells(headers, height)
+}
+
+// laneCards returns the display indices of the cards of column i in lane l.
+func (m *Model) laneCards(i, l int) []int {
+	var indices []int
+	for j, c := range m.displayColumns[i].Cards {
+		if m.laneOf(c) == l {
+			indices = append(indices, j)
+		}
+	}
+	return indices
+}
+
+// laneHeight is the height of the tallest cell of lane l, at least one line.
+func (m *Model) laneHeight(l int, widths []int) int {
+	height := 1
+	for i, col := range m.displayColumns {
+		h := 0
+		for _, j := range m.laneCards(i, l) {
+			h += lipgloss.Height(renderCard(col.Cards[j], m, i, j, m.cardContentWidth(widths[i])))
+		}
+		if h > height {
+			height = h
+		}
+	}
+	return height
+}
+
+// laneBands lays out the lanes from laneOffset that fit in height, each
+// below a one-line label.
+func (m *Model) laneBands(height int, widths []int) []laneBand {
+	var bands []laneBand
+	remaining := height
+	for l := m.laneOffset; l < len(m.lanes) && remaining >= 2; l++ {
+		band := laneBand{lane: l, height: m.laneHeight(l, widths)}
+		if band.height > remaining-1 {
+			band.height = remaining - 1
+			band.clipped = true
+		}
+		bands = append(bands, band)
+		remaining -= band.height + 1
+	}
+	return bands
+}
+
+// laneAreaHeight is the height left for the lanes below the column headers.
+func (m *Model) laneAreaHeight(widths []int) int {
+	h := m.boardHeight() - lipgloss.Height(renderLaneHeaders(m, widths))
+	if h < 0 {
+		return 0
+	}
+	return h
+}
+
+// ensureLaneVisible scrolls the lanes so that the focused lane is shown in
+// full, or at least at the top.
+func (m *Model) ensureLaneVisible() {
+	l := m.currentLane()
+	if l < m.laneOffset || m.laneOffset >= len(m.lanes) {
+		m.laneOffset = l
+		return
+	}
+	widths := laneColumnWidths(m)
+	area := m.laneAreaHeight(widths)
+	for m.laneOffset < l {
+		visible := false
+		for _, band := range m.laneBands(area, widths) {
+			if band.lane == l {
+				visible = !band.clipped
+			}
+		}
+		if visible {
+			return
+		}
+		m.laneOffset++
+	}
+}
+
+func renderLaneLabel(m *Model, l int, focused bool) string {
+	count := 0
+	for i := range m.displayColumns {
+		count += len(m.laneCards(i, l))
+	}
+	style := laneLabelStyle
+	if focused {
+		style = focusedLaneLabelStyle
+	}
+	return style.Copy().Width(m.boardWidth()).MaxHeight(1).Render(fmt.Sprintf("%s %d", laneTitle(m.lanes[l], m.laneField), count))
+}
+
+// renderLaneCell draws the cards of column i in lane l. The focused cell
+// scrolls to keep the focused card in view.
+func renderLaneCell(m *Model, i, l, width, height int) string {
+	col := m.displayColumns[i]
+	indices := m.laneCards(i, l)
+	contentW := m.cardContentWidth(width)
+
+	rendered := make([]string, len(indices))
+	for k, j := range indices {
+		rendered[k] = renderCard(col.Cards[j], m, i, j, contentW)
+	}
+
+	start := 0
+	if focus := m.currentFocusedCard(); i == m.focusedColumn && focus > 0 {
+		for k, j := range indices {
+			if j != focus-1 {
+				continue
+			}
+			used := 0
+			for start = k; start >= 0; start-- {
+				used += lipgloss.Height(rendered[start])
+				if used > height {
+					break
+				}
+			}
+			start++
+			if start > k {
+				start = k
+			}
+		}
+	}
+
+	var shown []string
+	used := 0
+	for _, r := range rendered[start:] {
+		h := lipgloss.Height(r)
+		if used+h > height && len(shown) > 0 {
+			break
+		}
+		shown = append(shown, r)
+		used += h
+	}
+	return columnStyle.Copy().Width(width).Height(height).MaxHeight(height).Render(strings.Join(shown, "\n"))
+}
+
+// renderLaneBoard draws the column headers once and below them a band per
+// lane, each holding a cell per column.
+func renderLaneBoard(m *Model, height int) string {
+	widths := laneColumnWidths(m)
+	headers := renderLaneHeaders(m, widths)
+	rows := []string{headers}
+
+	focusedLane := m.currentLane()
+	for _, band := range m.laneBands(height-lipgloss.Height(headers), widths) {
+		rows = append(rows, renderLaneLabel(m, band.lane, band.lane == focusedLane))
+		var cells []string
+		for i := range m.displayColumns {
+			cells = append(cells, renderLaneCell(m, i, band.lane, widths[i], band.height))
+		}
+		rows = append(rows, joinLaneCells(cells, band.height))
+	}
+
+	board := lipgloss.JoinVertical(lipgloss.Left, rows...)
+	return lipgloss.NewStyle().Height(height).MaxHeight(height).Render(board)
+}
@@ -0,0 +1,104 @@
+// internal/tui/macros.go
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxMacroDepth caps macros that play macros, since a macro that plays
+// itself would never end.
+const maxMacroDepth = 20
+
+// feedKey passes key to the key parser. While a macro is being recorded, the
+// record-macro action takes no register: it stops the recording.
+func (m *Model) feedKey(km keymap, key string) (keyInput, bool) {
+	input, done := m.input.feed(km, key)
+	if !done && m.recording != "" && m.input.argAction == "record-macro" {
+		// Why: The keys that stopped the recording are not part of it.
+		m.recorded = m.recorded[:max(len(m.recorded)-len(m.input.typed), 0)]
+		m.input.reset()
+		return keyInput{action: "record-macro"}, true
+	}
+	return input, done
+}
+
+// recordMacro starts recording the keys typed into macro name, or stops the
+// recording under way. An upper-case name appends to the macro of the
+// lower-case letter.
+func (m *Model) recordMacro(name string) tea.Cmd {
+	if m.recording != "" {
+		return m.stopRecording()
+	}
+	if !isMarkName(name) {
+		m.statusMessage = fmt.Sprintf("Invalid register: %s", name)
+		return clearStatusCmd(2 * time.Second)
+	}
+	m.recording = name
+	m.recorded = nil
+	return nil
+}
+
+func (m *Model) stopRecording() tea.Cmd {
+	name := strings.ToLower(m.recording)
+	keys := m.recorded
+	if name != m.recording {
+		keys = append(append([]tea.KeyMsg(nil), m.macros[name]...), keys...)
+	}
+	if m.macros == nil {
+		m.macros = make(map[string][]tea.KeyMsg)
+	}
+	m.macros[name] = keys
+	m.recording = ""
+	m.recorded = nil
+	m.statusMessage = fmt.Sprintf("Recorded @%s (%d keys)", name, len(keys))
+	return clearStatusCmd(2 * time.Second)
+}
+
+// playMacro types the keys of macro name count times. The name @ plays the
+// macro played last, and : repeats the last command.
+func (m *Model) playMacro(name string, count int) tea.Cmd {
+	switch name {
+	case ":":
+		if m.lastCommand == "" {
+			m.statusMessage = "No command to repeat"
+			return clearStatusCmd(2 * time.Second)
+		}
+		var cmds []tea.Cmd
+		for i := 0; i < count; i++ {
+			cmds = append(cmds, m.ExecuteCommand(m.lastCommand))
+		}
+		return tea.Batch(cmds...)
+	case "@":
+		if m.lastMacro == "" {
+			m.statusMessage = "No macro played yet"
+			return clearStatusCmd(2 * time.Second)
+		}
+		name = m.lastMacro
+	}
+	name = strings.ToLower(name)
+	keys, ok := m.macros[name]
+	if !ok {
+		m.statusMessage = fmt.Sprintf("Macro not recorded: %s", name)
+		return clearStatusCmd(2 * time.Second)
+	}
+	if m.macroDepth >= maxMacroDepth {
+		m.statusMessage = "Macros play each other too deeply"
+		return clearStatusCmd(2 * time.Second)
+	}
+
+	m.lastMacro = name
+	m.macroDepth++
+	defer func() { m.macroDepth-- }()
+	var cmds []tea.Cmd
+	for i := 0; i < count; i++ {
+		for _, k := range keys {
+			_, cmd := m.handleMode(k)
+			cmds = append(cmds, cmd)
+		}
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,339 @@
+// internal/tui/markdown.go
+package tui
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	mdHeading1Style = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("205")).
+			Bold(true).
+			Underline(true)
+
+	mdHeading2Style = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("205")).
+			Bold(true)
+
+	mdHeadingStyle = lipgloss.NewStyle().Bold(true)
+
+	mdBoldStyle   = lipgloss.NewStyle().Bold(true)
+	mdItalicStyle = lipgloss.NewStyle().Italic(true)
+	mdStrikeStyle = lipgloss.NewStyle().Strikethrough(true)
+
+	mdCodeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("203")).
+			Background(lipgloss.Color("236"))
+
+	mdCodeBlockStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("250")).
+				Background(lipgloss.Color("236"))
+
+	mdLinkStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("39")).
+			Underline(true)
+
+	mdURLStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240"))
+
+	mdQuoteStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("245")).
+			Italic(true)
+
+	mdRuleStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("238"))
+
+	mdCheckedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("76"))
+)
+
+var (
+	mdHeadingRegex  = regexp.MustCompile(`^ {0,3}(#{1,6})(?:\s+(.*?))?\s*#*\s*$`)
+	mdRuleRegex     = regexp.MustCompile(`^ {0,3}([-*_])(?:\s*[-*_]){2,}\s*$`)
+	mdListItemRegex = regexp.MustCompile(`^(\s*)([-*+]|\d{1,9}[.)])(?:\s+(.*))?$`)
+	mdQuoteRegex    = regexp.MustCompile(`^ {0,3}>\s?(.*)$`)
+	mdTaskRegex     = regexp.MustCompile(`^\[([ xX])\](?:\s+|$)`)
+)
+
+// renderMarkdown renders a card body for the terminal and returns its lines,
+// wrapped to width. It covers what cards commonly hold: headings, paragraphs,
+// lists and task lists, block quotes, code blocks, rules and inline
+// emphasis, code and links. Anything else is shown as text.
+func renderMarkdown(src string, width int) []string {
+	out, _ := renderMarkdownCursor(src, width, -1)
+	return out
+}
+
+// renderMarkdownCursor is renderMarkdown with the list item starting on
+// source line cursorLine highlighted. It also returns the output line the
+// item starts on, or -1.
+func renderMarkdownCursor(src string, width, cursorLine int) (out []string, cursorRow int) {
+	if width < 1 {
+		width = 1
+	}
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	cursorRow = -1
+
+	var paragraph []string
+	blank := func() {
+		if len(out) > 0 && out[len(out)-1] != "" {
+			out = append(out, "")
+		}
+	}
+	flush := func() {
+		if len(paragraph) > 0 {
+			out = append(out, wrapMarkdown(renderInline(strings.Join(paragraph, " ")), width)...)
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if fence := codeFence(trimmed); fence != "" {
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, strings.ReplaceAll(lines[i], "\t", "    "))
+			}
+			for _, c := range code {
+				out = append(out, mdCodeBlockStyle.Copy().Width(width).MaxWidth(width).Render(" "+c))
+			}
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flush()
+			blank()
+
+		case mdHeadingRegex.MatchString(line):
+			flush()
+			blank()
+			m := mdHeadingRegex.FindStringSubmatch(line)
+			style := mdHeadingStyle
+			switch len(m[1]) {
+			case 1:
+				style = mdHeading1Style
+			case 2:
+				style = mdHeading2Style
+			}
+			out = append(out, wrapMarkdown(style.Render(renderInline(m[2])), width)...)
+
+		case mdRuleRegex.MatchString(line):
+			flush()
+			out = append(out, mdRuleStyle.Render(strings.Repeat("─", width)))
+
+		case mdQuoteRegex.MatchString(line):
+			flush()
+			var quoted []string
+			for ; i < len(lines) && mdQuoteRegex.MatchString(lines[i]); i++ {
+				quoted = append(quoted, mdQuoteRegex.FindStringSubmatch(lines[i])[1])
+			}
+			i--
+			gutter := mdRuleStyle.Render("│ ")
+			for _, q := range renderMarkdown(strings.Join(quoted, "\n"), width-2) {
+				out = append(out, gutter+mdQuoteStyle.Render(q))
+			}
+
+		case mdListItemRegex.MatchString(line) && (len(paragraph) == 0 || interruptsParagraph(line)):
+			flush()
+			m := mdListItemRegex.FindStringSubmatch(line)
+			text := m[3]
+			cursor := i == cursorLine
+			// Why: Lazy continuation lines belong to the item above.
+			for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" && !isBlockStart(lines[i+1]) {
+				i++
+				text += " " + strings.TrimSpace(lines[i])
+			}
+			if cursor {
+				cursorRow = len(out)
+			}
+			out = append(out, renderListItem(m[1], m[2], text, width, cursor)...)
+
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return out, cursorRow
+}
+
+// codeFence returns the fence opening a code block on line, or "".
+func codeFence(trimmed string) string {
+	for _, fence := range []string{"```", "~~~"} {
+		if strings.HasPrefix(trimmed, fence) {
+			return fence
+		}
+	}
+	return ""
+}
+
+// interruptsParagraph reports whether the list item on line may start while
+// a paragraph is open. As in CommonMark, ordered items must start at 1, so
+// that a wrapped sentence ending in a number does not turn into a list.
+func interruptsParagraph(line string) bool {
+	m := mdListItemRegex.FindStringSubmatch(line)
+	return m[2][0] < '0' || m[2][0] > '9' || strings.TrimRight(m[2], ".)") == "1"
+}
+
+func isBlockStart(line string) bool {
+	return codeFence(strings.TrimSpace(line)) != "" || mdHeadingRegex.MatchString(line) ||
+		mdRuleRegex.MatchString(line) || mdQuoteRegex.MatchString(line) || mdListItemRegex.MatchString(line)
+}
+
+// renderListItem draws a list item with a hanging indent. Two spaces of
+// indentation make one nesting level; task items get a check box.
+func renderListItem(indent, marker, text string, width int, cursor bool) []string {
+	level := len(strings.ReplaceAll(indent, "\t", "  ")) / 2
+	bullet := "•"
+	if marker[0] >= '0' && marker[0] <= '9' {
+		bullet = marker
+	}
+	if m := mdTaskRegex.FindStringSubmatch(text); m != nil {
+		text = text[len(m[0]):]
+		if m[1] == " " {
+			bullet = "☐"
+		} else {
+			bullet = mdCheckedStyle.Render("☑")
+			text = mdStrikeStyle.Render(text)
+		}
+	}
+
+	if cursor {
+		bullet = checklistCursorStyle.Render(bullet)
+	}
+	prefix := strings.Repeat("  ", level) + bullet + " "
+	prefixWidth := lipgloss.Width(prefix)
+	if prefixWidth >= width {
+		prefix, prefixWidth = "", 0
+	}
+	lines := wrapMarkdown(renderInline(text), width-prefixWidth)
+	for i := range lines {
+		if i == 0 {
+			lines[i] = prefix + lines[i]
+		} else {
+			lines[i] = strings.Repeat(" ", prefixWidth) + lines[i]
+		}
+	}
+	return lines
+}
+
+// wrapMarkdown word-wraps styled text to width.
+func wrapMarkdown(text string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+	wrapped := lipgloss.NewStyle().Width(width).Render(text)
+	lines := strings.Split(wrapped, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+	return lines
+}
+
+// renderInline styles code spans, strong and emphasized text, strikethrough
+// and links. Unmatched delimiters are kept as they are.
+func renderInline(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", s[i+1]) >= 0:
+			b.WriteByte(s[i+1])
+			i += 2
+			continue
+
+		case c == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end >= 0 {
+				b.WriteString(mdCodeStyle.Render(s[i+1 : i+1+end]))
+				i += end + 2
+				continue
+			}
+
+		case strings.HasPrefix(s[i:], "**"), strings.HasPrefix(s[i:], "__"), strings.HasPrefix(s[i:], "~~"):
+			delim := s[i : i+2]
+			if end := strings.Index(s[i+2:], delim); end > 0 && canOpen(s, i, 2) {
+				style := mdBoldStyle
+				if delim == "~~" {
+					style = mdStrikeStyle
+				}
+				b.WriteString(style.Render(renderInline(s[i+2 : i+2+end])))
+				i += end + 4
+				continue
+			}
+
+		case c == '*' || c == '_':
+			if end := strings.IndexByte(s[i+1:], c); end > 0 && canOpen(s, i, 1) {
+				b.WriteString(mdItalicStyle.Render(renderInline(s[i+1 : i+1+end])))
+				i += end + 2
+				continue
+			}
+
+		case c == '[':
+			if text, url, n := parseInlineLink(s[i:]); n > 0 {
+				b.WriteString(mdLinkStyle.Render(renderInline(text)))
+				if url != text {
+					b.WriteString(" " + mdURLStyle.Render("("+url+")"))
+				}
+				i += n
+				continue
+			}
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String()
+}
+
+// canOpen reports whether the delimiter of length n at s[i] can open emphasis:
+// it must be followed by a non-space and, for underscores, must not be inside
+// a word such as snake_case.
+func canOpen(s string, i, n int) bool {
+	if i+n >= len(s) || s[i+n] == ' ' {
+		return false
+	}
+	if s[i] == '_' && i > 0 {
+		r, _ := utf8.DecodeLastRuneInString(s[:i])
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}
+	return true
+}
+
+// parseInlineLink parses "[text](url)" at the start of s and returns its
+// parts and length, or a length of 0.
+func parseInlineLink(s string) (text, url string, n int) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				if !strings.HasPrefix(s[i+1:], "(") {
+					return "", "", 0
+				}
+				end := strings.IndexByte(s[i+2:], ')')
+				if end < 0 {
+					return "", "", 0
+				}
+				url = strings.Trim(strings.TrimSpace(s[i+2:i+2+end]), "<>")
+				return s[1:i], url, i + 3 + end
+			}
+		}
+	}
+	return "", "", 0
+}
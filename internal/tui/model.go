@@ -3,6 +3,7 @@ package tui
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 	"os"
@@ -15,6 +16,7 @@ import (
 	"kanban/internal/column"
 	"kanban/internal/history"
 	"kanban/internal/fs"
+	"kanban/internal/watch"
 )
 
 type clearStatusMsg struct{}
@@ -33,6 +35,9 @@ const (
 	visualMode
 	searchMode
 	fzfMode
+	trashMode
+	fsckMode
+	registersMode
 )
 
 type searchResult struct {
@@ -46,7 +51,22 @@ type boardSession struct {
 	columnCardFocus []int
 	scrollOffset    int
 	doneColumnName  string
+	autoDone        bool
 	showHidden      bool
+	internalTrash   bool
+	filter          *cardFilter
+	laneField       string
+	preview         bool
+	previewDock     string
+	history         *history.History
+	lock            *fs.Lock
+	readOnly        bool
+	config          fs.Config
+	marks           map[string]fs.Position
+	jumps           []fs.Position
+	jumpIndex       int
+	registers       map[string][]card.Card
+	cut             map[string]struct{}
 }
 
 type Model struct {
@@ -55,6 +75,24 @@ type Model struct {
 	boardStack        []boardSession
 
 	displayColumns    []*column.Column
+	// filteredColumns backs displayColumns while a filter or swimlanes are
+	// active.
+	filteredColumns   []column.Column
+	filter            *cardFilter
+	// laneField is the card field swimlanes group by, "" without swimlanes.
+	laneField         string
+	lanes             []lane
+	focusedLane       int
+	laneOffset        int
+	// preview shows the focused card in a pane docked at previewDock.
+	preview           bool
+	previewDock       string
+	previewScroll     int
+	previewScrollKey  string
+	// checklistIndex is the checklist item under the cursor in the preview,
+	// for the card checklistKey.
+	checklistIndex    int
+	checklistKey      string
 	focusedColumn     int
 	columnCardFocus   []int
 	mode              mode
@@ -62,20 +100,63 @@ type Model struct {
 	width             int
 	height            int
 	selected          map[string]struct{}
-	clipboard         []card.Card
-	isCut             bool
+	// registers map a register name to the cards yanked or cut into it;
+	// cut holds the cards the next paste of them moves.
+	registers         map[string][]card.Card
+	cut               map[string]struct{}
 	scrollOffset      int
 	createCardMode    string
-	lastGPress        time.Time
-	lastYPress        time.Time
-	lastDPress        time.Time
+	// config is the configuration the key bindings, theme and defaults
+	// below come from.
+	config            fs.Config
+	normalKeys        keymap
+	visualKeys        keymap
+	// input holds the keys of a normal or visual mode command being typed.
+	input             keyParser
+	// marks map a letter to a card or column header set with m.
+	marks             map[string]fs.Position
+	// jumps is the jumplist, oldest first; jumpIndex is the entry C-o and
+	// C-i last went to, or len(jumps).
+	jumps             []fs.Position
+	jumpIndex         int
+	// searchOrigin is where focus was when search mode was entered.
+	searchOrigin      fs.Position
+	defaultCreateMode string
+	editor            string
 	visualSelectStart int
 	doneColumnName    string
+	// autoDone moves a card to the done column once its checklist is complete.
+	autoDone          bool
 	showHidden        bool
+	internalTrash     bool
 	history           *history.History
+	// watchSnapshot is the state of the board's files after our last change.
+	watchSnapshot     watch.Snapshot
+	// unsaved is set while the last write of kanban.md failed.
+	unsaved           bool
+	reloadConflict    bool
+	// lock is held while this instance may write the board; without it the
+	// board is read-only.
+	lock              *fs.Lock
+	readOnly          bool
 	lastCommand       string
+	// lastChange is the last command that changed the board, for .
+	lastChange        change
 	statusMessage     string
 	fzf               FZFModel
+	trashItems        []trashItem
+	trashCursor       int
+	registerNames     []string
+	registerCursor    int
+	// macros map a letter to the keys recorded with q. recording is the
+	// macro being recorded into recorded, "" if none.
+	macros            map[string][]tea.KeyMsg
+	recording         string
+	recorded          []tea.KeyMsg
+	lastMacro         string
+	macroDepth        int
+	fsckProblems      []fs.Problem
+	fsckCursor        int
 
 	completionMatches      []string
 	completionIndex        int
@@ -88,6 +169,9 @@ type Model struct {
 func NewModel(b board.Board, state *fs.AppState) Model {
 	ti := textinput.New()
 	ti.Prompt = ":"
+	lock, lockErr := fs.AcquireLock()
+	h, histErr := loadHistory(lockErr != nil)
+	cfg, cfgErr := fs.LoadConfig()
 
 	m := Model{
 		board:             b,
@@ -95,20 +179,43 @@ func NewModel(b board.Board, state *fs.AppState) Model {
 		mode:              normalMode,
 		textInput:         ti,
 		selected:          make(map[string]struct{}),
-		clipboard:         []card.Card{},
+		cut:               make(map[string]struct{}),
 		scrollOffset:      0,
-		createCardMode:    "prepend",
 		visualSelectStart: -1,
 		doneColumnName:    state.DoneColumn,
+		autoDone:          state.AutoDone,
 		showHidden:        state.ShowHidden,
-		history:           history.New(),
+		internalTrash:     state.InternalTrash,
+		laneField:         validLaneField(state.Lanes),
+		preview:           isPreviewDock(state.Preview),
+		previewDock:       previewDockOf(state.Preview),
+		marks:             state.Marks,
+		jumps:             state.Jumps,
+		jumpIndex:         len(state.Jumps),
+		history:           h,
+		lock:              lock,
+		readOnly:          lockErr != nil,
 		searchResults:     []searchResult{},
 		fzf:               NewFZFModel(),
 		completionMatches:      []string{},
 		completionIndex:        -1,
 		currentSearchResultIdx: -1,
 	}
+	if err := m.applyConfig(cfg); cfgErr == nil {
+		cfgErr = err
+	}
+	m.restoreRegisters(state.Registers)
 	m.updateDisplayColumns()
+	m.syncWatch()
+	if histErr != nil {
+		m.statusMessage = histErr.Error()
+	}
+	if cfgErr != nil {
+		m.statusMessage = "config: " + cfgErr.Error()
+	}
+	if lockErr != nil {
+		m.statusMessage = "Opened read-only: " + lockErr.Error()
+	}
 
 	m.columnCardFocus = make([]int, len(m.displayColumns))
 
@@ -139,6 +246,25 @@ func (m *Model) updateDisplayColumns() {
 	if m.showHidden && m.board.Archived.CardCount() > 0 {
 		m.displayColumns = append(m.displayColumns, &m.board.Archived)
 	}
+
+	m.lanes = nil
+	if m.filter == nil && m.laneField == "" {
+		m.filteredColumns = nil
+		return
+	}
+	m.filteredColumns = make([]column.Column, len(m.displayColumns))
+	for i, col := range m.displayColumns {
+		if m.filter != nil {
+			m.filteredColumns[i] = m.filter.filterColumn(*col)
+		} else {
+			m.filteredColumns[i] = *col
+			m.filteredColumns[i].Cards = append([]card.Card(nil), col.Cards...)
+		}
+		m.displayColumns[i] = &m.filteredColumns[i]
+	}
+	if m.laneField != "" {
+		m.groupByLane()
+	}
 }
 
 func (m Model) State() fs.AppState {
@@ -146,8 +272,29 @@ func (m Model) State() fs.AppState {
 		FocusedColumn: m.FocusedColumn(),
 		FocusedCard:   m.FocusedCard(),
 		DoneColumn:    m.doneColumnName,
+		AutoDone:      m.autoDone,
 		ShowHidden:    m.showHidden,
+		InternalTrash: m.internalTrash,
+		Lanes:         m.laneField,
+		Preview:       m.savedPreview(),
+		Marks:         m.marks,
+		Jumps:         m.jumps,
+		Registers:     m.savedRegisters(),
+	}
+}
+
+// ReadOnly reports whether another instance holds the lock of the board.
+func (m Model) ReadOnly() bool {
+	return m.readOnly
+}
+
+// saveState writes the state of the board unless it is read-only, in which
+// case the state file belongs to the instance holding the lock.
+func (m Model) saveState() error {
+	if m.readOnly {
+		return nil
 	}
+	return fs.SaveState(m.State())
 }
 
 func (m Model) FocusedColumn() int {
@@ -175,7 +322,7 @@ func (m *Model) setCurrentFocusedCard(focus int) {
 }
 
 func (m *Model) Init() tea.Cmd {
-	return nil
+	return watchTickCmd()
 }
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -191,21 +338,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case fzfCardSelectedMsg:
 		m.mode = normalMode
 		m.fzf.Blur()
-		found := false
-		for colIdx, col := range m.displayColumns {
-			for cardIdx, c := range col.Cards {
-				if c.UUID == msg.card.UUID {
-					m.focusedColumn = colIdx
-					m.setCurrentFocusedCard(cardIdx + 1)
-					m.ensureFocusedCardIsVisible()
-					found = true
-					break
-				}
-			}
-			if found {
-				break
-			}
-		}
+		from := m.position()
+		m.focusCard(msg.card.UUID)
+		m.recordJump(from)
 		return m, nil
 
 	case fzfCancelledMsg:
@@ -217,6 +352,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.statusMessage = ""
 		return m, nil
 
+	case watchTickMsg:
+		return m, tea.Batch(m.checkExternalChanges(), watchTickCmd())
+
 	case editorFinishedMsg:
 		if msg.err != nil {
 			return m, nil
@@ -227,9 +365,17 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if err != nil {
 				return m, nil
 			}
-			m.displayColumns[m.focusedColumn].Cards[currentFocus-1] = updatedCard
-			fs.WriteBoard(m.board)
+			if target := m.findCard(updatedCard.UUID); target != nil {
+				updatedCard.Annotation = target.Annotation
+				*target = updatedCard
+			}
+			m.updateDisplayColumns()
+			if err := m.writeBoard(); err != nil {
+				m.syncWatch()
+				return m, m.writeFailed(err)
+			}
 		}
+		m.syncWatch()
 		return m, nil
 
 	case boardSwitchedMsg:
@@ -239,8 +385,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// Why: Save the state of the current board before navigating away.
-		currentState := m.State()
-		if err := fs.SaveState(currentState.FocusedColumn, currentState.FocusedCard, currentState.DoneColumn, currentState.ShowHidden); err != nil {
+		if err := m.saveState(); err != nil {
 			m.statusMessage = fmt.Sprintf("Error saving state: %v", err)
 			return m, clearStatusCmd(4 * time.Second)
 		}
@@ -251,7 +396,22 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			columnCardFocus: m.columnCardFocus,
 			scrollOffset:    m.scrollOffset,
 			doneColumnName:  m.doneColumnName,
+			autoDone:        m.autoDone,
 			showHidden:      m.showHidden,
+			internalTrash:   m.internalTrash,
+			filter:          m.filter,
+			laneField:       m.laneField,
+			preview:         m.preview,
+			previewDock:     m.previewDock,
+			history:         m.history,
+			lock:            m.lock,
+			readOnly:        m.readOnly,
+			config:          m.config,
+			marks:           m.marks,
+			jumps:           m.jumps,
+			jumpIndex:       m.jumpIndex,
+			registers:       m.registers,
+			cut:             m.cut,
 		}
 		m.boardStack = append(m.boardStack, session)
 
@@ -262,11 +422,23 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, clearStatusCmd(4 * time.Second)
 		}
 
+		if err := m.history.Save(m.board); err != nil {
+			m.statusMessage = fmt.Sprintf("Error saving undo journal: %v", err)
+		}
 		m.reInit(msg.board, &msg.state)
-		m.statusMessage = "Switched to board: " + msg.board.Path
+		if !m.readOnly {
+			m.statusMessage = "Switched to board: " + msg.board.Path
+		}
 		return m, clearStatusCmd(2 * time.Second)
 	}
 
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.recording != "" {
+		m.recorded = append(m.recorded, keyMsg)
+	}
+	return m.handleMode(msg)
+}
+
+func (m *Model) handleMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch m.mode {
 	case fzfMode:
@@ -279,6 +451,12 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmd = m.updateVisualMode(msg)
 	case searchMode:
 		cmd = m.updateSearchMode(msg)
+	case trashMode:
+		cmd = m.updateTrashMode(msg)
+	case fsckMode:
+		cmd = m.updateFsckMode(msg)
+	case registersMode:
+		cmd = m.updateRegistersMode(msg)
 	default: // normalMode
 		cmd = m.updateNormalMode(msg)
 	}
@@ -288,27 +466,45 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m *Model) reInit(b board.Board, state *fs.AppState) {
 	ti := textinput.New()
 	ti.Prompt = ":"
+	lock, lockErr := fs.AcquireLock()
+	h, histErr := loadHistory(lockErr != nil)
+	cfg, cfgErr := fs.LoadConfig()
 
 	// Preserve window size
 	width, height := m.width, m.height
 	boardStack := m.boardStack
+	// Why: Macros are not tied to a board, and may be recorded across gf.
+	macros, recording, recorded, lastMacro := m.macros, m.recording, m.recorded, m.lastMacro
 
 	// Re-initialize the model struct
 	*m = Model{
 		width:             width,
 		height:            height,
 		boardStack:        boardStack,
+		macros:            macros,
+		recording:         recording,
+		recorded:          recorded,
+		lastMacro:         lastMacro,
 		board:             b,
 		mode:              normalMode,
 		textInput:         ti,
 		selected:          make(map[string]struct{}),
-		clipboard:         []card.Card{},
+		cut:               make(map[string]struct{}),
 		scrollOffset:      0,
-		createCardMode:    "prepend",
 		visualSelectStart: -1,
 		doneColumnName:    state.DoneColumn,
+		autoDone:          state.AutoDone,
 		showHidden:        state.ShowHidden,
-		history:           history.New(),
+		internalTrash:     state.InternalTrash,
+		laneField:         validLaneField(state.Lanes),
+		preview:           isPreviewDock(state.Preview),
+		previewDock:       previewDockOf(state.Preview),
+		marks:             state.Marks,
+		jumps:             state.Jumps,
+		jumpIndex:         len(state.Jumps),
+		history:           h,
+		lock:              lock,
+		readOnly:          lockErr != nil,
 		searchResults:     []searchResult{},
 		fzf:               NewFZFModel(),
 		completionMatches:      []string{},
@@ -317,7 +513,21 @@ func (m *Model) reInit(b board.Board, state *fs.AppState) {
 	}
 
 	m.fzf.SetSize(m.width, m.height)
+	if err := m.applyConfig(cfg); cfgErr == nil {
+		cfgErr = err
+	}
+	m.restoreRegisters(state.Registers)
 	m.updateDisplayColumns()
+	m.syncWatch()
+	if histErr != nil {
+		m.statusMessage = histErr.Error()
+	}
+	if cfgErr != nil {
+		m.statusMessage = "config: " + cfgErr.Error()
+	}
+	if lockErr != nil {
+		m.statusMessage = "Opened read-only: " + lockErr.Error()
+	}
 
 	m.columnCardFocus = make([]int, len(m.displayColumns))
 
@@ -345,12 +555,23 @@ func (m Model) View() string {
 	if m.mode == fzfMode {
 		return m.fzf.View()
 	}
+	if m.mode == trashMode {
+		return renderTrash(&m)
+	}
+	if m.mode == fsckMode {
+		return renderFsck(&m)
+	}
+	if m.mode == registersMode {
+		return renderRegisters(&m)
+	}
 
 	statusBar := renderStatusBar(&m)
-	statusBarHeight := lipgloss.Height(statusBar)
-	boardHeight := m.height - statusBarHeight
+	boardHeight := m.boardHeight()
 
 	boardView := renderBoard(&m, boardHeight)
+	if m.preview {
+		boardView = renderWithPreview(&m, boardView, boardHeight)
+	}
 
 	if statusBar != "" {
 		return lipgloss.JoinVertical(lipgloss.Left, boardView, statusBar)
@@ -358,9 +579,9 @@ func (m Model) View() string {
 	return boardView
 }
 
-func (m *Model) deleteCards(cardsToDelete []card.Card) {
+func (m *Model) deleteCards(cardsToDelete []card.Card) tea.Cmd {
 	if len(cardsToDelete) == 0 {
-		return
+		return nil
 	}
 
 	deletedUUIDs := make(map[string]struct{})
@@ -370,7 +591,7 @@ func (m *Model) deleteCards(cardsToDelete []card.Card) {
 	}
 
 	if len(deletedUUIDs) == 0 {
-		return
+		return nil
 	}
 
 	for i := range m.board.Columns {
@@ -392,17 +613,15 @@ func (m *Model) deleteCards(cardsToDelete []card.Card) {
 	}
 	m.board.Archived.Cards = keptArchived
 
-	keptClipboard := m.clipboard[:0]
-	for _, c := range m.clipboard {
-		if _, wasDeleted := deletedUUIDs[c.UUID]; !wasDeleted {
-			keptClipboard = append(keptClipboard, c)
-		}
-	}
-	m.clipboard = keptClipboard
+	m.dropFromRegisters(deletedUUIDs)
 
 	m.selected = make(map[string]struct{})
-	fs.WriteBoard(m.board)
+	err := m.writeBoard()
 	m.clampFocusedCard()
+	if err != nil {
+		return m.writeFailed(err)
+	}
+	return nil
 }
 
 func (m *Model) clampFocusedCard() {
@@ -422,15 +641,17 @@ func (m *Model) clampFocusedCard() {
 }
 
 func (m Model) isCardMarkedForCut(uuid string) bool {
-	if !m.isCut {
-		return false
-	}
-	for _, c := range m.clipboard {
-		if c.UUID == uuid {
-			return true
-		}
+	_, isCut := m.cut[uuid]
+	return isCut
+}
+
+// doneColumn returns the title of the done column: the one set for the board
+// with :set done, or else the one of the config.
+func (m *Model) doneColumn() string {
+	if m.doneColumnName != "" {
+		return m.doneColumnName
 	}
-	return false
+	return m.config.DoneColumn
 }
 
 func (m *Model) cardWidth(columnWidth int) int {
@@ -454,7 +675,7 @@ func (m *Model) getFocusedColumnWidth() int {
 		numSeparators = 0
 	}
 
-	availableWidth := m.width - numSeparators
+	availableWidth := m.boardWidth() - numSeparators
 	baseColumnWidth := availableWidth / numColumns
 	remainder := availableWidth % numColumns
 
@@ -475,7 +696,7 @@ func (m *Model) getCardRenderHeight(c card.Card) int {
 		return 2 // just border height
 	}
 	contentStyle := lipgloss.NewStyle().Width(contentW)
-	contentHeight := lipgloss.Height(contentStyle.Render(c.Title))
+	contentHeight := lipgloss.Height(contentStyle.Render(cardBody(c, c.Title)))
 	borderHeight := 2 // For lipgloss.RoundedBorder
 	return contentHeight + borderHeight
 }
@@ -484,15 +705,8 @@ func (m *Model) getColumnHeaderHeight() int {
 	if m.width == 0 || len(m.displayColumns) == 0 || m.focusedColumn >= len(m.displayColumns) {
 		return 1
 	}
-	col := m.displayColumns[m.focusedColumn]
 	isHeaderFocused := m.currentFocusedCard() == 0
-
-	header := fmt.Sprintf("%s %d", col.Title, col.CardCount())
-
-	headerStyle := columnHeaderStyle
-	if isHeaderFocused {
-		headerStyle = focusedColumnHeaderStyle
-	}
+	header, headerStyle := columnHeader(m, m.focusedColumn, isHeaderFocused)
 
 	colWidth := m.getFocusedColumnWidth()
 	if colWidth == 0 {
@@ -511,6 +725,10 @@ func (m *Model) ensureFocusedCardIsVisible() {
 	if m.height == 0 || len(m.displayColumns) == 0 || m.focusedColumn >= len(m.displayColumns) {
 		return
 	}
+	if m.laneField != "" {
+		m.ensureLaneVisible()
+		return
+	}
 	currentFocus := m.currentFocusedCard()
 	if currentFocus == 0 {
 		m.scrollOffset = 0
@@ -524,10 +742,8 @@ func (m *Model) ensureFocusedCardIsVisible() {
 		return
 	}
 
-	statusBar := renderStatusBar(m)
-	statusBarHeight := lipgloss.Height(statusBar)
 	headerHeight := m.getColumnHeaderHeight()
-	cardAreaH := m.height - statusBarHeight - headerHeight
+	cardAreaH := m.boardHeight() - headerHeight
 	if cardAreaH < 0 {
 		cardAreaH = 0
 	}
@@ -605,29 +821,36 @@ func (m *Model) getSelectedOrFocusedCards() []*card.Card {
 		cardIndex := m.currentFocusedCard() - 1
 		focusedCol := m.displayColumns[m.focusedColumn]
 		if cardIndex < len(focusedCol.Cards) {
-			cardsToMove = append(cardsToMove, &focusedCol.Cards[cardIndex])
+			if c := m.findCard(focusedCol.Cards[cardIndex].UUID); c != nil {
+				cardsToMove = append(cardsToMove, c)
+			}
 		}
 	}
 	return cardsToMove
 }
 
-func (m *Model) moveCards(cardsToMove []*card.Card, destCol *column.Column) {
+// moveCards moves cards into destCol and returns the first error of any
+// card that could not be moved; those cards stay where they were.
+func (m *Model) moveCards(cardsToMove []*card.Card, destCol *column.Column) error {
 	if len(cardsToMove) == 0 {
-		return
+		return nil
 	}
 
 	successfullyMovedCards := make([]card.Card, 0, len(cardsToMove))
 	movedUUIDs := make(map[string]struct{})
+	var firstErr error
 	for _, c := range cardsToMove {
 		err := fs.MoveCard(c, *destCol)
 		if err == nil {
 			successfullyMovedCards = append(successfullyMovedCards, *c)
 			movedUUIDs[c.UUID] = struct{}{}
+		} else if firstErr == nil {
+			firstErr = err
 		}
 	}
 
 	if len(successfullyMovedCards) == 0 {
-		return
+		return firstErr
 	}
 
 	// Remove from ALL source columns by creating new slices.
@@ -651,18 +874,102 @@ func (m *Model) moveCards(cardsToMove []*card.Card, destCol *column.Column) {
 	m.board.Archived.Cards = keptArchived
 
 	destCol.Cards = append(destCol.Cards, successfullyMovedCards...)
+	return firstErr
+}
+
+// exceedsWIPLimit reports whether putting incoming into col would break its
+// WIP limit. Moved cards already in col do not count; copies always do.
+func (m *Model) exceedsWIPLimit(col *column.Column, incoming []card.Card, copies bool) bool {
+	n := len(incoming)
+	if !copies {
+		n = 0
+		for _, c := range incoming {
+			if indexOfCard(col.Cards, c.UUID) == len(col.Cards) {
+				n++
+			}
+		}
+	}
+	return col.WouldExceedLimit(n)
+}
+
+func (m *Model) wipLimitMessage(col *column.Column, command string) tea.Cmd {
+	m.statusMessage = fmt.Sprintf("'%s' is at its WIP limit of %d; use :%s! to force", col.Title, col.Limit, command)
+	return clearStatusCmd(4 * time.Second)
 }
 
 func (m *Model) clearSelection() {
 	m.selected = make(map[string]struct{})
-	m.clipboard = []card.Card{}
-	m.isCut = false
+	m.cut = make(map[string]struct{})
 	m.visualSelectStart = -1
 	m.mode = normalMode
 }
 
-func (m *Model) saveStateForUndo() {
-	m.history.Push(m.board)
+func (m *Model) saveStateForUndo(op string) {
+	m.history.Push(m.board, op)
+}
+
+// restoreBoard replaces the board with target, the board an undo or redo of
+// op leads to, and brings the files on disk in line with it. verb is "Undo"
+// or "Redo".
+func (m *Model) restoreBoard(target board.Board, op, verb string) tea.Cmd {
+	target.Path = m.board.Path
+	keepBoardText(&target, m.board)
+
+	err := fs.RestoreBoard(m.board, &target)
+	m.board = target
+	if writeErr := m.writeBoard(); err == nil {
+		err = writeErr
+	}
+	m.updateAndResizeFocus()
+
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("%s %s: %v", verb, op, err)
+		return clearStatusCmd(5 * time.Second)
+	}
+	m.statusMessage = fmt.Sprintf("%s %s", verb, op)
+	return clearStatusCmd(2 * time.Second)
+}
+
+// keepBoardText copies the text kanban.md holds besides the card lists from
+// current into target. That text is edited by hand rather than through the
+// journal, so undo and redo leave it as it is now.
+func keepBoardText(target *board.Board, current board.Board) {
+	target.Preamble = current.Preamble
+	text := map[string]column.Column{current.Archived.Title: current.Archived}
+	for _, col := range current.Columns {
+		text[col.Title] = col
+	}
+	cols := []*column.Column{&target.Archived}
+	for i := range target.Columns {
+		cols = append(cols, &target.Columns[i])
+	}
+	for _, col := range cols {
+		if cur, ok := text[col.Title]; ok {
+			col.Description = cur.Description
+			col.Notes = cur.Notes
+		}
+	}
+}
+
+// loadHistory opens the undo journal of the board in the current directory,
+// falling back to an in-memory history if it cannot be read. A read-only
+// instance keeps its history in memory so it never clobbers the journal of
+// the instance holding the lock.
+func loadHistory(readOnly bool) (*history.History, error) {
+	if readOnly {
+		return history.New(), nil
+	}
+	// Why: The path must be absolute because nested boards change the working
+	// directory while the parent's journal is still held in memory.
+	path, err := filepath.Abs(filepath.Join(fs.DataDirName, fs.JournalFileName))
+	if err != nil {
+		return history.New(), fmt.Errorf("could not load undo journal: %w", err)
+	}
+	h, err := history.Load(path)
+	if err != nil {
+		return history.New(), fmt.Errorf("could not load undo journal: %w", err)
+	}
+	return h, nil
 }
 
 func (m *Model) updateAndResizeFocus() {
@@ -682,9 +989,14 @@ func (m *Model) updateAndResizeFocus() {
 }
 
 func (m *Model) jumpTo(res searchResult) {
+	from := m.position()
 	m.focusedColumn = res.colIndex
 	m.setCurrentFocusedCard(res.cardIndex)
 	m.ensureFocusedCardIsVisible()
+	// Why: Incremental search is recorded as one jump once it is confirmed.
+	if m.mode != searchMode {
+		m.recordJump(from)
+	}
 }
 
 func (m *Model) openFZF() tea.Cmd {
@@ -707,6 +1019,7 @@ func (m *Model) openFZF() tea.Cmd {
 		}
 	}
 	m.fzf.SetItems(items)
+	m.fzf.SetPreviewWidth(m.cardContentWidth(m.getFocusedColumnWidth()))
 	return m.fzf.Focus()
 }
 
@@ -716,12 +1029,22 @@ func (m *Model) popBoard() tea.Cmd {
 	}
 
 	// Why: Save the state of the board we are leaving.
-	currentState := m.State()
-	if err := fs.SaveState(currentState.FocusedColumn, currentState.FocusedCard, currentState.DoneColumn, currentState.ShowHidden); err != nil {
+	if err := m.saveState(); err != nil {
 		m.statusMessage = fmt.Sprintf("Error saving state: %v", err)
 		return clearStatusCmd(4 * time.Second)
 	}
 
+	if err := m.history.Save(m.board); err != nil {
+		m.statusMessage = fmt.Sprintf("Error saving undo journal: %v", err)
+		return clearStatusCmd(4 * time.Second)
+	}
+
+	// Why: Deletions are only pending until the board is left; card paths
+	// are relative, so they must be trashed before changing directory.
+	if err := fs.FlushTrash(m.board.Trash, m.internalTrash); err != nil {
+		m.statusMessage = fmt.Sprintf("Error trashing cards: %v", err)
+	}
+
 	lastSession := m.boardStack[len(m.boardStack)-1]
 	m.boardStack = m.boardStack[:len(m.boardStack)-1]
 
@@ -737,17 +1060,41 @@ func (m *Model) popBoard() tea.Cmd {
 	m.columnCardFocus = lastSession.columnCardFocus
 	m.scrollOffset = lastSession.scrollOffset
 	m.doneColumnName = lastSession.doneColumnName
+	m.autoDone = lastSession.autoDone
 	m.showHidden = lastSession.showHidden
+	m.internalTrash = lastSession.internalTrash
+	m.filter = lastSession.filter
+	m.laneField = lastSession.laneField
+	m.laneOffset = 0
+	m.preview = lastSession.preview
+	m.previewDock = lastSession.previewDock
+	m.history = lastSession.history
+	if err := m.lock.Release(); err != nil {
+		m.statusMessage = fmt.Sprintf("Error releasing lock: %v", err)
+	}
+	m.lock = lastSession.lock
+	m.readOnly = lastSession.readOnly
+	m.applyConfig(lastSession.config)
+	m.marks = lastSession.marks
+	m.jumps = lastSession.jumps
+	m.jumpIndex = lastSession.jumpIndex
+	m.registers = lastSession.registers
+	m.cut = lastSession.cut
 
 	m.updateDisplayColumns()
+	m.syncWatch()
 	m.clampFocusedCard()
 	m.ensureFocusedCardIsVisible()
 
-	m.statusMessage = "Returned to board: " + m.board.Path
+	if m.statusMessage == "" {
+		m.statusMessage = "Returned to board: " + m.board.Path
+	}
 	return clearStatusCmd(2 * time.Second)
 }
 
-func (m *Model) ExecuteCommand(commandStr string) tea.Cmd {
+// lookupCommand splits commandStr into the command and its arguments, and
+// finds the command in the registry.
+func lookupCommand(commandStr string) (commandInfo, string, string, bool) {
 	parts := strings.SplitN(strings.TrimSpace(commandStr), " ", 2)
 	command := parts[0]
 	var args string
@@ -765,11 +1112,21 @@ func (m *Model) ExecuteCommand(commandStr string) tea.Cmd {
 		// This handles aliases like 'wq' which don't have a '!' variant
 		cmdInfo, ok = commandRegistry[command]
 	}
+	return cmdInfo, command, args, ok
+}
 
+func (m *Model) ExecuteCommand(commandStr string) tea.Cmd {
+	if rng, rest := splitRange(strings.TrimSpace(commandStr)); rng != "" {
+		return m.executeRange(rng, rest)
+	}
+	cmdInfo, command, args, ok := lookupCommand(commandStr)
 	if !ok {
 		m.statusMessage = fmt.Sprintf("Not a command: %s", command)
 		return clearStatusCmd(2 * time.Second)
 	}
+	if cmdInfo.modifies && m.readOnly {
+		return m.refuseReadOnly()
+	}
 
 	// Why: The command itself is responsible for saving state for undo,
 	// because not all commands are undoable (e.g., view changes).
@@ -777,5 +1134,42 @@ func (m *Model) ExecuteCommand(commandStr string) tea.Cmd {
 }
 
 func (m *Model) Cleanup() error {
-	return fs.FlushTrash(m.board.Trash)
+	histErr := m.history.Save(m.board)
+	trashErr := fs.FlushTrash(m.board.Trash, m.internalTrash)
+	lockErr := m.lock.Release()
+
+	// Why: The boards we navigated away from still hold their pending
+	// deletions, whose paths are relative to each board's directory.
+	for i := len(m.boardStack) - 1; i >= 0; i-- {
+		session := m.boardStack[i]
+		if err := os.Chdir(session.board.Path); err != nil {
+			if trashErr == nil {
+				trashErr = err
+			}
+		} else if err := fs.FlushTrash(session.board.Trash, session.internalTrash); trashErr == nil {
+			trashErr = err
+		}
+		if err := session.lock.Release(); lockErr == nil {
+			lockErr = err
+		}
+	}
+	if len(m.boardStack) > 0 {
+		if err := os.Chdir(m.board.Path); err != nil && trashErr == nil {
+			trashErr = err
+		}
+	}
+
+	if trashErr != nil {
+		return trashErr
+	}
+	if histErr != nil {
+		return histErr
+	}
+	return lockErr
+}
+
+// refuseReadOnly reports why an edit was not made in a read-only board.
+func (m *Model) refuseReadOnly() tea.Cmd {
+	m.statusMessage = "Board is read-only while another kanban instance has it open"
+	return clearStatusCmd(3 * time.Second)
 }
@@ -0,0 +1,101 @@
+// internal/tui/motion.go
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"kanban/internal/card"
+)
+
+// motionTarget returns the card (1-based, 0 for the column header) of the
+// focused column that motion moves to, repeated count times. A count makes
+// "first" and "last" go to that card, as in 10G.
+func (m *Model) motionTarget(motion string, count int) int {
+	cards := m.displayColumns[m.focusedColumn].Cards
+	focus := m.currentFocusedCard()
+	n := max(count, 1)
+	switch motion {
+	case "up":
+		return max(focus-n, 0)
+	case "down":
+		return min(focus+n, len(cards))
+	case "first", "last":
+		if count > 0 {
+			return min(count, len(cards))
+		}
+		if motion == "first" {
+			return min(1, len(cards))
+		}
+		return len(cards)
+	case "block-end":
+		for i := 0; i < n; i++ {
+			focus = m.blockEnd(cards, focus)
+		}
+	case "block-start":
+		for i := 0; i < n; i++ {
+			focus = m.blockStart(cards, focus)
+		}
+	}
+	return focus
+}
+
+// sameBlock reports whether cards i and j are in the same block: the same
+// swimlane, or the column when there are none.
+func (m *Model) sameBlock(cards []card.Card, i, j int) bool {
+	return m.laneField == "" || m.laneOf(cards[i]) == m.laneOf(cards[j])
+}
+
+// blockEnd returns the last card of the block of card focus, or of the next
+// block if focus already is the last one.
+func (m *Model) blockEnd(cards []card.Card, focus int) int {
+	if len(cards) == 0 {
+		return 0
+	}
+	i := max(focus-1, 0)
+	if focus > 0 && i+1 < len(cards) && !m.sameBlock(cards, i, i+1) {
+		i++
+	}
+	for i+1 < len(cards) && m.sameBlock(cards, i, i+1) {
+		i++
+	}
+	return i + 1
+}
+
+// blockStart returns the first card of the block of card focus, or of the
+// previous block if focus already is the first one.
+func (m *Model) blockStart(cards []card.Card, focus int) int {
+	if focus <= 1 {
+		return focus
+	}
+	i := focus - 1
+	if !m.sameBlock(cards, i-1, i) {
+		i--
+	}
+	for i > 0 && m.sameBlock(cards, i-1, i) {
+		i--
+	}
+	return i + 1
+}
+
+// operate puts the cards an operator applies to into the register of input:
+// the cards from the focused one to the target of the motion, or count cards
+// from the focused one when the operator was repeated, as in 3dd.
+func (m *Model) operate(input keyInput) tea.Cmd {
+	cards := m.displayColumns[m.focusedColumn].Cards
+	focus := m.currentFocusedCard()
+	if focus == 0 || focus > len(cards) {
+		return nil
+	}
+	from, to := focus, focus+max(input.count, 1)-1
+	if input.motion != "" {
+		to = m.motionTarget(input.motion, input.count)
+	}
+	if to < from {
+		from, to = to, from
+	}
+	from, to = max(from, 1), min(to, len(cards))
+
+	m.selected = make(map[string]struct{})
+	m.setCurrentFocusedCard(from)
+	m.ensureFocusedCardIsVisible()
+	return m.storeRegister(input.register, cards[from-1:to], input.action == "cut")
+}
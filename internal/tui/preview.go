@@ -0,0 +1,285 @@
+// internal/tui/preview.go
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"kanban/internal/card"
+)
+
+// previewDocks are the sides of the board the preview pane can sit on.
+var previewDocks = []string{"right", "bottom"}
+
+var (
+	previewTitleStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("205")).
+				Bold(true)
+
+	previewLabelStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("245"))
+
+	previewRuleStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("238"))
+
+	previewStyle = lipgloss.NewStyle().Padding(0, 1)
+)
+
+func isPreviewDock(dock string) bool {
+	for _, d := range previewDocks {
+		if d == dock {
+			return true
+		}
+	}
+	return false
+}
+
+// previewDockOf returns the dock saved in the board state, defaulting to the
+// right side.
+func previewDockOf(saved string) string {
+	if isPreviewDock(saved) {
+		return saved
+	}
+	return previewDocks[0]
+}
+
+// savedPreview is the dock saved in the board state while the pane is open.
+func (m *Model) savedPreview() string {
+	if !m.preview {
+		return ""
+	}
+	return m.previewDock
+}
+
+// previewSize returns the size of the preview pane, without the rule that
+// separates it from the board.
+func (m *Model) previewSize() (width, height int) {
+	area := m.height - lipgloss.Height(renderStatusBar(m))
+	if m.previewDock == "bottom" {
+		return m.width, area * 2 / 5
+	}
+	return m.width * 2 / 5, area
+}
+
+// boardWidth is the width left for the board by the preview pane.
+func (m *Model) boardWidth() int {
+	if !m.preview || m.previewDock == "bottom" {
+		return m.width
+	}
+	w, _ := m.previewSize()
+	return m.width - w - 1
+}
+
+// boardHeight is the height left for the board by the status bar and the
+// preview pane.
+func (m *Model) boardHeight() int {
+	h := m.height - lipgloss.Height(renderStatusBar(m))
+	if m.preview && m.previewDock == "bottom" {
+		_, ph := m.previewSize()
+		h -= ph + 1
+	}
+	if h < 0 {
+		return 0
+	}
+	return h
+}
+
+// previewKey identifies what the preview shows, so that its scroll position
+// is dropped when focus moves elsewhere.
+func (m *Model) previewKey() string {
+	if len(m.displayColumns) == 0 {
+		return ""
+	}
+	col := m.displayColumns[m.focusedColumn]
+	if focus := m.currentFocusedCard(); focus > 0 && focus <= len(col.Cards) {
+		return col.Cards[focus-1].UUID
+	}
+	return "column:" + col.Title
+}
+
+// previewOffset is the first line of the preview shown.
+func (m *Model) previewOffset() int {
+	if m.previewScrollKey != m.previewKey() {
+		return 0
+	}
+	return m.previewScroll
+}
+
+// scrollPreview scrolls the preview by delta lines.
+func (m *Model) scrollPreview(delta int) tea.Cmd {
+	if !m.preview {
+		return nil
+	}
+	width, height := m.previewSize()
+	offset := m.previewOffset() + delta
+	if maxOffset := len(previewLines(m, width-previewStyle.GetHorizontalPadding())) - height; offset > maxOffset {
+		offset = maxOffset
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	m.previewScroll = offset
+	m.previewScrollKey = m.previewKey()
+	return nil
+}
+
+// previewHalfPage is how far C-d and C-u scroll the preview.
+func (m *Model) previewHalfPage() int {
+	_, height := m.previewSize()
+	if height < 2 {
+		return 1
+	}
+	return height / 2
+}
+
+func (m *Model) togglePreview() tea.Cmd {
+	m.preview = !m.preview
+	m.ensureFocusedCardIsVisible()
+	return nil
+}
+
+func cmdPreview(m *Model, command, args string) tea.Cmd {
+	arg := strings.ToLower(strings.TrimSpace(args))
+	switch {
+	case arg == "":
+		return m.togglePreview()
+	case arg == "off":
+		m.preview = false
+	case isPreviewDock(arg):
+		m.preview = true
+		m.previewDock = arg
+	default:
+		m.statusMessage = fmt.Sprintf("Unknown preview position: %s (use %s or off)", arg, strings.Join(previewDocks, ", "))
+		return clearStatusCmd(3 * time.Second)
+	}
+	m.ensureFocusedCardIsVisible()
+	return nil
+}
+
+// previewLines lays out the details of the focused card, or of the focused
+// column while its header is focused, wrapped to width.
+func previewLines(m *Model, width int) []string {
+	lines, _ := previewLayout(m, width)
+	return lines
+}
+
+// previewLayout is previewLines that also returns the line of the checklist
+// item under the cursor, or -1.
+func previewLayout(m *Model, width int) ([]string, int) {
+	if len(m.displayColumns) == 0 || width < 1 {
+		return nil, -1
+	}
+	col := m.displayColumns[m.focusedColumn]
+	var lines []string
+	field := func(label, value string) {
+		if value != "" {
+			lines = append(lines, previewLabelStyle.Render(fmt.Sprintf("%-9s", label))+value)
+		}
+	}
+
+	focus := m.currentFocusedCard()
+	if focus == 0 || focus > len(col.Cards) {
+		src := m.sourceColumn(m.focusedColumn)
+		lines = append(lines, previewTitleStyle.Render(col.Title), "")
+		field("Cards", fmt.Sprint(src.CardCount()))
+		if src.Limit > 0 {
+			field("Limit", fmt.Sprint(src.Limit))
+		}
+		if len(src.Description) > 0 {
+			lines = append(lines, "", strings.Join(src.Description, "\n"))
+		}
+		if len(src.Notes) > 0 {
+			lines = append(lines, "", strings.Join(src.Notes, "\n"))
+		}
+		return wrapLines(lines, width), -1
+	}
+
+	c := col.Cards[focus-1]
+	lines = append(lines, previewTitleStyle.Render(c.Title), "")
+	field("Column", col.Title)
+	if len(c.Tags) > 0 {
+		var chips []string
+		for _, t := range c.Tags {
+			chips = append(chips, renderTagChip(t))
+		}
+		field("Tags", strings.Join(chips, " "))
+	}
+	if rank := c.PriorityRank(); rank < len(priorityColors) {
+		field("Priority", priorityStyle.Copy().Background(priorityColors[rank]).Render(c.Priority))
+	}
+	field("Due", renderDue(c))
+	field("Assignee", c.Assignee)
+	field("Lane", c.Lane)
+	field("Link", c.Link)
+	field("Created", formatPreviewTime(c.CreatedAt))
+	field("Modified", formatPreviewTime(c.ModifiedAt))
+	field("Path", c.Path)
+
+	field("Tasks", renderChecklistBadge(c))
+
+	lines = wrapLines(lines, width)
+	if !c.HasContent() {
+		return lines, -1
+	}
+	lines = append(lines, previewRuleStyle.Render(strings.Repeat("─", width)))
+	body, cursorRow := previewContent(c, width, m.checklistCursor())
+	if cursorRow >= 0 {
+		cursorRow += len(lines)
+	}
+	return append(lines, body...), cursorRow
+}
+
+// previewContent renders the body of c for the preview pane, marking
+// checklist item cursor.
+func previewContent(c card.Card, width, cursor int) ([]string, int) {
+	line := -1
+	if cursor >= 0 && cursor < len(c.Checklist) {
+		line = c.Checklist[cursor].Line
+	}
+	return renderMarkdownCursor(c.Content, width, line)
+}
+
+func formatPreviewTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// wrapLines wraps every line to width and returns the resulting lines.
+func wrapLines(lines []string, width int) []string {
+	wrapped := lipgloss.NewStyle().Width(width).Render(strings.Join(lines, "\n"))
+	return strings.Split(wrapped, "\n")
+}
+
+func renderPreview(m *Model, width, height int) string {
+	lines := previewLines(m, width-previewStyle.GetHorizontalPadding())
+	offset := m.previewOffset()
+	if offset > len(lines)-height {
+		offset = len(lines) - height
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	end := offset + height
+	if end > len(lines) {
+		end = len(lines)
+	}
+	return previewStyle.Copy().Width(width).Height(height).MaxHeight(height).Render(strings.Join(lines[offset:end], "\n"))
+}
+
+// renderWithPreview places the preview pane next to or below the board.
+func renderWithPreview(m *Model, boardView string, boardHeight int) string {
+	width, height := m.previewSize()
+	pane := renderPreview(m, width, height)
+	boardView = lipgloss.NewStyle().Width(m.boardWidth()).Height(boardHeight).Render(boardView)
+	if m.previewDock == "bottom" {
+		rule := previewRuleStyle.Render(strings.Repeat("─", m.width))
+		return lipgloss.JoinVertical(lipgloss.Left, boardView, rule, pane)
+	}
+	rule := previewRuleStyle.Render(strings.TrimSuffix(strings.Repeat("│\n", height), "\n"))
+	return lipgloss.JoinHorizontal(lipgloss.Top, boardView, rule, pane)
+}
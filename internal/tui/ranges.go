@@ -0,0 +1,323 @@
+// internal/tui/ranges.go
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"kanban/internal/card"
+	"kanban/internal/column"
+	"kanban/internal/fs"
+)
+
+// visualRange is put before the command typed in visual mode, as in Vim.
+const visualRange = "'<,'>"
+
+// splitRange splits the range off the front of an ex command, as "2,$" of
+// "2,$tag x".
+func splitRange(commandStr string) (string, string) {
+	i := 0
+	for i < len(commandStr) {
+		switch ch := commandStr[i]; {
+		case ch == '\'' && i+1 < len(commandStr):
+			i += 2
+		case ch >= '0' && ch <= '9' || strings.IndexByte(".$%,+-", ch) >= 0:
+			i++
+		default:
+			return commandStr[:i], commandStr[i:]
+		}
+	}
+	return commandStr, ""
+}
+
+// rangeCards returns the first and last card (1-based) of the focused column
+// that rng spans. Addresses are card numbers, . for the focused card, $ for
+// the last one, '< and '> for the first and last selected card and 'a for
+// the card of mark a, each followed by any number of +n and -n; % is 1,$.
+func (m *Model) rangeCards(rng string) (int, int, error) {
+	if len(m.displayColumns) == 0 {
+		return 0, 0, errors.New("no cards")
+	}
+	if rng == "%" {
+		rng = "1,$"
+	}
+	addrs := strings.Split(rng, ",")
+	if len(addrs) > 2 {
+		return 0, 0, errors.New("more than two addresses")
+	}
+	lines := make([]int, len(addrs))
+	for i, addr := range addrs {
+		n, err := m.cardAddress(addr)
+		if err != nil {
+			return 0, 0, err
+		}
+		lines[i] = n
+	}
+	from, to := lines[0], lines[len(lines)-1]
+	if from > to {
+		from, to = to, from
+	}
+	if from < 1 || to > len(m.displayColumns[m.focusedColumn].Cards) {
+		return 0, 0, errors.New("no such card")
+	}
+	return from, to, nil
+}
+
+// cardAddress returns the card number addr refers to in the focused column.
+func (m *Model) cardAddress(addr string) (int, error) {
+	cards := m.displayColumns[m.focusedColumn].Cards
+	n := m.currentFocusedCard()
+	i := 0
+	switch {
+	case addr == "":
+		return 0, errors.New("missing address")
+	case addr[0] >= '0' && addr[0] <= '9':
+		for i < len(addr) && addr[i] >= '0' && addr[i] <= '9' {
+			i++
+		}
+		n, _ = strconv.Atoi(addr[:i])
+	case addr[0] == '.':
+		i = 1
+	case addr[0] == '$':
+		n, i = len(cards), 1
+	case addr[0] == '\'' && len(addr) > 1:
+		i = 2
+		var err error
+		if n, err = m.markAddress(addr[1:2]); err != nil {
+			return 0, err
+		}
+	}
+
+	for i < len(addr) {
+		sign := 1
+		switch addr[i] {
+		case '+':
+		case '-':
+			sign = -1
+		default:
+			return 0, fmt.Errorf("bad address %s", addr)
+		}
+		i++
+		j := i
+		for j < len(addr) && addr[j] >= '0' && addr[j] <= '9' {
+			j++
+		}
+		offset := 1
+		if j > i {
+			offset, _ = strconv.Atoi(addr[i:j])
+		}
+		n += sign * offset
+		i = j
+	}
+	return n, nil
+}
+
+// markAddress returns the card number of mark name in the focused column.
+// The marks < and > are the first and last selected card.
+func (m *Model) markAddress(name string) (int, error) {
+	cards := m.displayColumns[m.focusedColumn].Cards
+	if name == "<" || name == ">" {
+		first, last := 0, 0
+		for i, c := range cards {
+			if _, ok := m.selected[c.UUID]; ok {
+				if first == 0 {
+					first = i + 1
+				}
+				last = i + 1
+			}
+		}
+		if first == 0 {
+			return 0, errors.New("no visual selection")
+		}
+		if name == "<" {
+			return first, nil
+		}
+		return last, nil
+	}
+
+	p, ok := m.marks[name]
+	if !ok {
+		return 0, fmt.Errorf("mark not set: %s", name)
+	}
+	if i := indexOfCard(cards, p.Card); p.Card != "" && i < len(cards) {
+		return i + 1, nil
+	}
+	return 0, fmt.Errorf("card of mark %s is not in this column", name)
+}
+
+// executeRange runs the command of commandStr on the cards rng spans, by
+// selecting them. Without a command, it focuses the last of them, as :5 does.
+func (m *Model) executeRange(rng, commandStr string) tea.Cmd {
+	from, to, err := m.rangeCards(rng)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Invalid range %s: %v", rng, err)
+		return clearStatusCmd(3 * time.Second)
+	}
+	if strings.TrimSpace(commandStr) == "" {
+		origin := m.position()
+		m.setCurrentFocusedCard(to)
+		m.ensureFocusedCardIsVisible()
+		m.recordJump(origin)
+		return nil
+	}
+	if info, command, _, ok := lookupCommand(commandStr); ok && !info.ranges {
+		m.statusMessage = fmt.Sprintf("No range allowed: %s", command)
+		return clearStatusCmd(3 * time.Second)
+	}
+
+	m.selected = make(map[string]struct{})
+	for _, c := range m.displayColumns[m.focusedColumn].Cards[from-1 : to] {
+		m.selected[c.UUID] = struct{}{}
+	}
+	cmd := m.ExecuteCommand(commandStr)
+	m.selected = make(map[string]struct{})
+	m.visualSelectStart = -1
+	return cmd
+}
+
+// findColumnArg finds the column arg names: by title, ignoring case, by the
+// start of a single title, or by its number counted from 1.
+func (m *Model) findColumnArg(arg string) (*column.Column, error) {
+	arg = strings.TrimSpace(arg)
+	if arg == "" {
+		return nil, errors.New("no column given")
+	}
+	var prefixed []*column.Column
+	for i := range m.board.Columns {
+		col := &m.board.Columns[i]
+		if strings.EqualFold(col.Title, arg) {
+			return col, nil
+		}
+		if strings.HasPrefix(strings.ToLower(col.Title), strings.ToLower(arg)) {
+			prefixed = append(prefixed, col)
+		}
+	}
+	if n, err := strconv.Atoi(arg); err == nil {
+		if n < 1 || n > len(m.board.Columns) {
+			return nil, fmt.Errorf("no column %d", n)
+		}
+		return &m.board.Columns[n-1], nil
+	}
+	switch len(prefixed) {
+	case 0:
+		return nil, fmt.Errorf("no column %s", arg)
+	case 1:
+		return prefixed[0], nil
+	}
+	titles := make([]string, len(prefixed))
+	for i, col := range prefixed {
+		titles[i] = col.Title
+	}
+	return nil, fmt.Errorf("%s is ambiguous (%s)", arg, strings.Join(titles, ", "))
+}
+
+// columnCompletions completes the column title in args, which may hold the
+// first words of a title with spaces.
+func columnCompletions(m *Model, args string) []string {
+	typed := strings.ToLower(args[:strings.LastIndex(args, " ")+1])
+	var candidates []string
+	for _, col := range m.board.Columns {
+		if strings.HasPrefix(strings.ToLower(col.Title), typed) {
+			candidates = append(candidates, col.Title[len(typed):])
+		}
+	}
+	return candidates
+}
+
+// cmdMove moves the selected or focused cards to the bottom of the column
+// named in args. A column at its WIP limit refuses them unless ! is given.
+func cmdMove(m *Model, command, args string) tea.Cmd {
+	destCol, err := m.findColumnArg(args)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("%s: %v", strings.TrimSuffix(command, "!"), err)
+		return clearStatusCmd(3 * time.Second)
+	}
+
+	cards := m.getSelectedOrFocusedCards()
+	var cardsToMove []*card.Card
+	for _, c := range cards {
+		if indexOfCard(destCol.Cards, c.UUID) == len(destCol.Cards) {
+			cardsToMove = append(cardsToMove, c)
+		}
+	}
+	if len(cardsToMove) == 0 {
+		m.clearSelection()
+		if len(cards) > 0 {
+			m.statusMessage = fmt.Sprintf("Already in '%s'", destCol.Title)
+			return clearStatusCmd(2 * time.Second)
+		}
+		return nil
+	}
+	incoming := make([]card.Card, len(cardsToMove))
+	for i, c := range cardsToMove {
+		incoming[i] = *c
+	}
+	if !strings.HasSuffix(command, "!") && m.exceedsWIPLimit(destCol, incoming, false) {
+		return m.wipLimitMessage(destCol, "move")
+	}
+
+	m.saveStateForUndo("move")
+	moveErr := m.moveCards(cardsToMove, destCol)
+	err = m.writeBoard()
+	m.clearSelection()
+	m.updateAndResizeFocus()
+	if err == nil {
+		err = moveErr
+	}
+	if err != nil {
+		return m.writeFailed(err)
+	}
+	m.statusMessage = fmt.Sprintf("Moved %d card(s) to '%s'", len(cardsToMove), destCol.Title)
+	return clearStatusCmd(2 * time.Second)
+}
+
+// cmdCopy copies the selected or focused cards to the bottom of the column
+// named in args.
+func cmdCopy(m *Model, command, args string) tea.Cmd {
+	destCol, err := m.findColumnArg(args)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("%s: %v", strings.TrimSuffix(command, "!"), err)
+		return clearStatusCmd(3 * time.Second)
+	}
+
+	cards := m.getSelectedOrFocusedCards()
+	if len(cards) == 0 {
+		m.clearSelection()
+		return nil
+	}
+	incoming := make([]card.Card, len(cards))
+	for i, c := range cards {
+		incoming[i] = *c
+	}
+	if !strings.HasSuffix(command, "!") && m.exceedsWIPLimit(destCol, incoming, true) {
+		return m.wipLimitMessage(destCol, "copy")
+	}
+
+	m.saveStateForUndo("copy")
+	var copyErr error
+	for _, c := range incoming {
+		newCard, err := fs.CopyCard(c, *destCol)
+		if err != nil {
+			if copyErr == nil {
+				copyErr = err
+			}
+			continue
+		}
+		destCol.Cards = append(destCol.Cards, newCard)
+	}
+	err = m.writeBoard()
+	m.clearSelection()
+	m.updateAndResizeFocus()
+	if err == nil {
+		err = copyErr
+	}
+	if err != nil {
+		return m.writeFailed(err)
+	}
+	m.statusMessage = fmt.Sprintf("Copied %d card(s) to '%s'", len(incoming), destCol.Title)
+	return clearStatusCmd(2 * time.Second)
+}
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+
+	"kanban/internal/card"
+	"kanban/internal/column"
+	"kanban/internal/fs"
+)
+
+func TestSplitRange(t *testing.T) {
+	tests := []struct {
+		command, rng, rest string
+	}{
+		{"tag x", "", "tag x"},
+		{"2,$tag x", "2,$", "tag x"},
+		{"%delete", "%", "delete"},
+		{".,.+2move Done", ".,.+2", "move Done"},
+		{"'<,'>archive", "'<,'>", "archive"},
+		{"'a,'bcut", "'a,'b", "cut"},
+		{"3", "3", ""},
+		{"'", "", "'"},
+		{"$-1,$", "$-1,$", ""},
+	}
+	for _, tt := range tests {
+		rng, rest := splitRange(tt.command)
+		if rng != tt.rng || rest != tt.rest {
+			t.Errorf("splitRange(%q) = %q, %q; want %q, %q", tt.command, rng, rest, tt.rng, tt.rest)
+		}
+	}
+}
+
+// rangeModel returns a model whose focused column holds five cards, the third
+// focused, the second and fourth selected and the fifth marked a.
+func rangeModel() *Model {
+	cards := make([]card.Card, 5)
+	for i := range cards {
+		cards[i] = card.Card{UUID: fmt.Sprint(i + 1), Title: fmt.Sprint("card ", i+1)}
+	}
+	col := column.New("Todo", ".kanban/Todo", cards...)
+	return &Model{
+		displayColumns:  []*column.Column{&col},
+		columnCardFocus: []int{3},
+		selected:        map[string]struct{}{"2": {}, "4": {}},
+		marks:           map[string]fs.Position{"a": {Card: "5"}, "b": {Column: "Todo"}},
+	}
+}
+
+func TestRangeCards(t *testing.T) {
+	tests := []struct {
+		rng      string
+		from, to int
+		err      bool
+	}{
+		{rng: "2", from: 2, to: 2},
+		{rng: "1,3", from: 1, to: 3},
+		{rng: "3,1", from: 1, to: 3},
+		{rng: "%", from: 1, to: 5},
+		{rng: ".", from: 3, to: 3},
+		{rng: ".,$", from: 3, to: 5},
+		{rng: ".+1", from: 4, to: 4},
+		{rng: ".-", from: 2, to: 2},
+		{rng: ".+1+1", from: 5, to: 5},
+		{rng: "$-2,$", from: 3, to: 5},
+		{rng: "'<,'>", from: 2, to: 4},
+		{rng: "'a", from: 5, to: 5},
+		{rng: "'a-1,'a", from: 4, to: 5},
+		{rng: "6", err: true},
+		{rng: "0,2", err: true},
+		{rng: ".+3", err: true},
+		{rng: "1,2,3", err: true},
+		{rng: "1,", err: true},
+		{rng: "1x", err: true},
+		{rng: "'z", err: true},
+		{rng: "'b", err: true},
+	}
+	for _, tt := range tests {
+		from, to, err := rangeModel().rangeCards(tt.rng)
+		if tt.err {
+			if err == nil {
+				t.Errorf("rangeCards(%q) = %d, %d; want an error", tt.rng, from, to)
+			}
+			continue
+		}
+		if err != nil || from != tt.from || to != tt.to {
+			t.Errorf("rangeCards(%q) = %d, %d, %v; want %d, %d", tt.rng, from, to, err, tt.from, tt.to)
+		}
+	}
+}
+
+func TestRangeCardsWithoutSelection(t *testing.T) {
+	m := rangeModel()
+	m.selected = nil
+	if _, _, err := m.rangeCards("'<,'>"); err == nil {
+		t.Error("rangeCards('<,'>) without a selection succeeded")
+	}
+}
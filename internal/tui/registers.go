@@ -0,0 +1,234 @@
+// internal/tui/registers.go
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"kanban/internal/card"
+)
+
+// unnamedRegister holds the last yank or cut; p pastes it when no register
+// is named.
+const unnamedRegister = `"`
+
+// historySize is the number of numbered registers, "1 to "9, that keep the
+// latest yanks and cuts, newest first.
+const historySize = 9
+
+// isRegisterName reports whether name can be pasted from: the unnamed
+// register, a letter or a digit of the yank history.
+func isRegisterName(name string) bool {
+	return name == unnamedRegister || isMarkName(name) || len(name) == 1 && name[0] >= '1' && name[0] <= '9'
+}
+
+// storeRegister puts cards into register name, "" for the unnamed one, and
+// into the yank history. An upper-case name appends to the register of the
+// lower-case letter. Cut cards are moved by the next paste of them.
+func (m *Model) storeRegister(name string, cards []card.Card, cut bool) tea.Cmd {
+	if name != "" && (!isRegisterName(name) || name[0] >= '1' && name[0] <= '9') {
+		m.statusMessage = fmt.Sprintf("Invalid register: %s", name)
+		return clearStatusCmd(2 * time.Second)
+	}
+	if len(cards) == 0 {
+		return nil
+	}
+	if m.registers == nil {
+		m.registers = make(map[string][]card.Card)
+	}
+	cards = append([]card.Card(nil), cards...)
+
+	for i := historySize; i > 1; i-- {
+		if prev, ok := m.registers[strconv.Itoa(i-1)]; ok {
+			m.registers[strconv.Itoa(i)] = prev
+		}
+	}
+	m.registers["1"] = cards
+	content := cards
+	switch lower := strings.ToLower(name); {
+	case name == "" || name == unnamedRegister:
+	case lower != name:
+		content = append(append([]card.Card(nil), m.registers[lower]...), cards...)
+		m.registers[lower] = content
+	default:
+		m.registers[name] = cards
+	}
+	m.registers[unnamedRegister] = content
+
+	m.cut = make(map[string]struct{})
+	if cut {
+		for _, c := range cards {
+			m.cut[c.UUID] = struct{}{}
+		}
+	}
+	if len(cards) > 1 {
+		verb := "Yanked"
+		if cut {
+			verb = "Cut"
+		}
+		m.statusMessage = fmt.Sprintf("%s %d cards", verb, len(cards))
+		if name != "" && name != unnamedRegister {
+			m.statusMessage += fmt.Sprintf(" into \"%s", strings.ToLower(name))
+		}
+		return clearStatusCmd(2 * time.Second)
+	}
+	return nil
+}
+
+// registerCards returns the cards of register name as they are now on the
+// board; cards no longer on it are returned as they were yanked.
+func (m *Model) registerCards(name string) []card.Card {
+	saved := m.registers[strings.ToLower(name)]
+	cards := make([]card.Card, len(saved))
+	for i, c := range saved {
+		cards[i] = c
+		if current := m.findCard(c.UUID); current != nil {
+			cards[i] = *current
+		}
+	}
+	return cards
+}
+
+// dropFromRegisters removes the cards with the given UUIDs from every
+// register and from the pending cut.
+func (m *Model) dropFromRegisters(uuids map[string]struct{}) {
+	for name, cards := range m.registers {
+		kept := make([]card.Card, 0, len(cards))
+		for _, c := range cards {
+			if _, drop := uuids[c.UUID]; !drop {
+				kept = append(kept, c)
+			}
+		}
+		m.registers[name] = kept
+	}
+	for uuid := range uuids {
+		delete(m.cut, uuid)
+	}
+}
+
+// savedRegisters returns the registers by the UUIDs of their cards, for the
+// state file.
+func (m Model) savedRegisters() map[string][]string {
+	var saved map[string][]string
+	for name, cards := range m.registers {
+		if len(cards) == 0 {
+			continue
+		}
+		if saved == nil {
+			saved = make(map[string][]string)
+		}
+		uuids := make([]string, len(cards))
+		for i, c := range cards {
+			uuids[i] = c.UUID
+		}
+		saved[name] = uuids
+	}
+	return saved
+}
+
+// restoreRegisters fills the registers from the state file, leaving out the
+// cards that are no longer on the board.
+func (m *Model) restoreRegisters(saved map[string][]string) {
+	m.registers = make(map[string][]card.Card)
+	for name, uuids := range saved {
+		if !isRegisterName(name) {
+			continue
+		}
+		for _, uuid := range uuids {
+			if c := m.findCard(uuid); c != nil {
+				m.registers[name] = append(m.registers[name], *c)
+			}
+		}
+	}
+}
+
+// registerOrder lists the registers that hold cards in the order the
+// registers popup shows them.
+func (m *Model) registerOrder() []string {
+	var names []string
+	add := func(name string) {
+		if len(m.registers[name]) > 0 {
+			names = append(names, name)
+		}
+	}
+	add(unnamedRegister)
+	for i := 1; i <= historySize; i++ {
+		add(strconv.Itoa(i))
+	}
+	for c := 'a'; c <= 'z'; c++ {
+		add(string(c))
+	}
+	return names
+}
+
+func cmdRegisters(m *Model, command, args string) tea.Cmd {
+	m.registerNames = m.registerOrder()
+	m.registerCursor = 0
+	m.statusMessage = ""
+	m.mode = registersMode
+	return nil
+}
+
+func (m *Model) updateRegistersMode(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q", "ctrl+c":
+		m.mode = normalMode
+		m.registerNames = nil
+
+	case "j", "down":
+		if m.registerCursor < len(m.registerNames)-1 {
+			m.registerCursor++
+		}
+
+	case "k", "up":
+		if m.registerCursor > 0 {
+			m.registerCursor--
+		}
+
+	case "enter", "p", "P":
+		if len(m.registerNames) == 0 {
+			return nil
+		}
+		if m.readOnly {
+			return m.refuseReadOnly()
+		}
+		name := m.registerNames[m.registerCursor]
+		m.mode = normalMode
+		m.registerNames = nil
+		return m.paste(name, keyMsg.String() == "P", false, 1)
+	}
+	return nil
+}
+
+func renderRegisters(m *Model) string {
+	lines := make([]string, len(m.registerNames))
+	for i, name := range m.registerNames {
+		cards := m.registerCards(name)
+		titles := make([]string, len(cards))
+		cut := false
+		for j, c := range cards {
+			titles[j] = c.Title
+			if _, ok := m.cut[c.UUID]; ok {
+				cut = true
+			}
+		}
+		lines[i] = fmt.Sprintf("\"%s  %s", name, strings.Join(titles, ", "))
+		if cut {
+			lines[i] += popupHintStyle.Render(" (cut)")
+		}
+	}
+
+	footer := popupHintStyle.Render("enter/p: paste after · P: paste before · esc: close")
+	if m.statusMessage != "" {
+		footer = m.statusMessage
+	}
+	return renderListPopup(m, "Registers", lines, m.registerCursor, "All registers are empty", footer)
+}
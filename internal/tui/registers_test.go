@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"kanban/internal/card"
+)
+
+// uuids renders the cards of a register for comparison.
+func uuids(cards []card.Card) string {
+	ids := make([]string, len(cards))
+	for i, c := range cards {
+		ids[i] = c.UUID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestStoreRegister(t *testing.T) {
+	type store struct {
+		name  string
+		cards string // UUIDs separated by commas
+	}
+	tests := []struct {
+		name   string
+		stores []store
+		want   map[string]string // Register to UUIDs; others must be empty
+	}{
+		{
+			name:   "unnamed",
+			stores: []store{{"", "a"}},
+			want:   map[string]string{`"`: "a", "1": "a"},
+		},
+		{
+			name:   "named",
+			stores: []store{{"x", "a,b"}},
+			want:   map[string]string{`"`: "a,b", "1": "a,b", "x": "a,b"},
+		},
+		{
+			name:   "append",
+			stores: []store{{"x", "a"}, {"X", "b,c"}},
+			want:   map[string]string{`"`: "a,b,c", "1": "b,c", "2": "a", "x": "a,b,c"},
+		},
+		{
+			name:   "append to empty register",
+			stores: []store{{"X", "a"}},
+			want:   map[string]string{`"`: "a", "1": "a", "x": "a"},
+		},
+		{
+			name:   "history rotation",
+			stores: []store{{"", "a"}, {"x", "b"}, {"", "c"}},
+			want:   map[string]string{`"`: "c", "1": "c", "2": "b", "3": "a", "x": "b"},
+		},
+		{
+			name: "history drops the oldest",
+			stores: []store{
+				{"", "a"}, {"", "b"}, {"", "c"}, {"", "d"}, {"", "e"},
+				{"", "f"}, {"", "g"}, {"", "h"}, {"", "i"}, {"", "j"},
+			},
+			want: map[string]string{
+				`"`: "j", "1": "j", "2": "i", "3": "h", "4": "g", "5": "f",
+				"6": "e", "7": "d", "8": "c", "9": "b",
+			},
+		},
+		{
+			name:   "numbered register refused",
+			stores: []store{{"", "a"}, {"2", "b"}},
+			want:   map[string]string{`"`: "a", "1": "a"},
+		},
+		{
+			name:   "invalid register refused",
+			stores: []store{{"!", "a"}},
+			want:   map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Model
+			for _, s := range tt.stores {
+				var cards []card.Card
+				for _, id := range strings.Split(s.cards, ",") {
+					cards = append(cards, card.Card{UUID: id})
+				}
+				m.storeRegister(s.name, cards, false)
+			}
+			for name, cards := range m.registers {
+				if got := uuids(cards); got != tt.want[name] {
+					t.Errorf("register %s = %q; want %q", name, got, tt.want[name])
+				}
+			}
+			for name, want := range tt.want {
+				if _, ok := m.registers[name]; !ok {
+					t.Errorf("register %s is empty; want %q", name, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreRegisterCut(t *testing.T) {
+	var m Model
+	m.storeRegister("", []card.Card{{UUID: "a"}, {UUID: "b"}}, true)
+	if _, ok := m.cut["a"]; !ok || len(m.cut) != 2 {
+		t.Errorf("cut = %v after cutting a and b", m.cut)
+	}
+	// Why: A later yank replaces the pending cut.
+	m.storeRegister("", []card.Card{{UUID: "c"}}, false)
+	if len(m.cut) != 0 {
+		t.Errorf("cut = %v after a yank; want none", m.cut)
+	}
+}
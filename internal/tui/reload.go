@@ -0,0 +1,193 @@
+// internal/tui/reload.go
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"kanban/internal/board"
+	"kanban/internal/card"
+	"kanban/internal/fs"
+	"kanban/internal/watch"
+)
+
+const conflictMessage = fs.BoardFileName + " changed on disk and the board has unsaved changes; :reload! discards them, :w! overwrites the file"
+
+type watchTickMsg struct{}
+
+func watchTickCmd() tea.Cmd {
+	return tea.Tick(watchInterval, func(t time.Time) tea.Msg {
+		return watchTickMsg{}
+	})
+}
+
+// writeBoard writes the board to kanban.md and remembers whether the
+// in-memory board is now out of sync with the file.
+func (m *Model) writeBoard() error {
+	err := fs.WriteBoard(m.board)
+	m.unsaved = err != nil
+	if errors.Is(err, fs.ErrBoardChanged) {
+		m.reloadConflict = true
+	}
+	return err
+}
+
+// writeFailed reports a failed write in the status bar.
+func (m *Model) writeFailed(err error) tea.Cmd {
+	if errors.Is(err, fs.ErrBoardChanged) {
+		m.statusMessage = conflictMessage
+	} else {
+		m.statusMessage = fmt.Sprintf("Write failed: %v", err)
+	}
+	return clearStatusCmd(5 * time.Second)
+}
+
+// syncWatch records the files on disk as known.
+func (m *Model) syncWatch() {
+	if snap, err := watch.Scan(); err == nil {
+		m.watchSnapshot = snap
+	}
+}
+
+// checkExternalChanges reloads whatever changed on disk since the last tick.
+// Card file edits are reloaded card by card; an external change to kanban.md
+// reloads the whole board unless that would discard unsaved changes. Files
+// that still hold what this process wrote are left alone.
+func (m *Model) checkExternalChanges() tea.Cmd {
+	if m.reloadConflict || m.watchSnapshot == nil {
+		return nil
+	}
+	snap, err := watch.Scan()
+	if err != nil {
+		return nil
+	}
+	changed := m.watchSnapshot.Changed(snap)
+	m.watchSnapshot = snap
+	if len(changed) == 0 {
+		return nil
+	}
+
+	var cardPaths []string
+	boardChanged := false
+	for _, path := range changed {
+		if path == fs.BoardFileName {
+			boardChanged = fs.BoardChangedOnDisk()
+		} else {
+			cardPaths = append(cardPaths, path)
+		}
+	}
+
+	if boardChanged {
+		if m.unsaved {
+			m.reloadConflict = true
+			m.statusMessage = conflictMessage
+			return clearStatusCmd(8 * time.Second)
+		}
+		return m.reload()
+	}
+
+	reloaded := 0
+	for _, path := range cardPaths {
+		target := m.findCardByPath(path)
+		if target == nil {
+			continue // Not on the board
+		}
+		updated, err := fs.LoadCard(target.Path)
+		if err != nil {
+			// Why: A card file that vanished or no longer parses cannot be
+			// patched in place; let LoadBoard decide what remains.
+			return m.reload()
+		}
+		if sameCard(*target, updated) {
+			continue
+		}
+		updated.Annotation = target.Annotation
+		*target = updated
+		reloaded++
+	}
+	if reloaded == 0 {
+		return nil
+	}
+	m.updateDisplayColumns()
+	m.clampFocusedCard()
+	m.statusMessage = fmt.Sprintf("Reloaded %d card(s) changed on disk", reloaded)
+	return clearStatusCmd(3 * time.Second)
+}
+
+// reload replaces the board with the one on disk, keeping the focused card.
+func (m *Model) reload() tea.Cmd {
+	b, err := fs.LoadBoard()
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Error reloading board: %v", err)
+		return clearStatusCmd(4 * time.Second)
+	}
+
+	focusedUUID := ""
+	if focus := m.currentFocusedCard(); focus > 0 && m.focusedColumn < len(m.displayColumns) {
+		focusedUUID = m.displayColumns[m.focusedColumn].Cards[focus-1].UUID
+	}
+
+	// Why: Deleted cards stay in the trash until exit, unless the reloaded
+	// board references them again.
+	b.Trash = keepUnlisted(m.board.Trash, b)
+	m.board = b
+	m.unsaved = false
+	m.reloadConflict = false
+	// Why: The journaled changes were made to the board before it changed on
+	// disk; undoing them now would revert the external edit.
+	m.history.Clear()
+	m.syncWatch()
+
+	m.updateAndResizeFocus()
+	if focusedUUID != "" {
+		m.focusCard(focusedUUID)
+	}
+	m.ensureFocusedCardIsVisible()
+
+	m.statusMessage = "Reloaded " + fs.BoardFileName + " from disk; undo history cleared"
+	return clearStatusCmd(3 * time.Second)
+}
+
+// findCardByPath returns the card stored at path, or nil.
+func (m *Model) findCardByPath(path string) *card.Card {
+	path = filepath.Clean(path)
+	for i := range m.board.Columns {
+		for j := range m.board.Columns[i].Cards {
+			if filepath.Clean(m.board.Columns[i].Cards[j].Path) == path {
+				return &m.board.Columns[i].Cards[j]
+			}
+		}
+	}
+	for j := range m.board.Archived.Cards {
+		if filepath.Clean(m.board.Archived.Cards[j].Path) == path {
+			return &m.board.Archived.Cards[j]
+		}
+	}
+	return nil
+}
+
+// sameCard reports whether updated, as read back from disk, holds nothing
+// that c does not, i.e. whether the file still is what this process wrote.
+func sameCard(c, updated card.Card) bool {
+	// Why: Writing a card stamps ModifiedAt on disk only, and times read back
+	// lose their location and monotonic reading.
+	if !c.CreatedAt.Equal(updated.CreatedAt) {
+		return false
+	}
+	c.CreatedAt, c.ModifiedAt = updated.CreatedAt, updated.ModifiedAt
+	c.Annotation = updated.Annotation
+	return fs.SameCardData(c, updated)
+}
+
+func keepUnlisted(cards []card.Card, b board.Board) []card.Card {
+	var kept []card.Card
+	for _, c := range cards {
+		if _, _, err := b.FindCard(c.UUID); err != nil {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
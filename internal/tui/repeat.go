@@ -0,0 +1,89 @@
+// internal/tui/repeat.go
+package tui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// change is the last command that changed the board, kept so that . can
+// repeat it at the focus of the time.
+type change struct {
+	// input is the normal or visual mode command.
+	input keyInput
+	// visual is the number of cards a visual mode command applied to, 0 for
+	// a normal mode command.
+	visual int
+	// command is the ex command, and createMode where it put new cards, when
+	// the change was made in command mode.
+	command    string
+	createMode string
+}
+
+// isChangeCommand reports whether the ex command commandStr writes the board.
+func isChangeCommand(commandStr string) bool {
+	_, rest := splitRange(strings.TrimSpace(commandStr))
+	info, _, _, ok := lookupCommand(rest)
+	return ok && info.modifies
+}
+
+// repeatChange repeats the last change. A count replaces the count of a
+// normal mode command, and a paste from the yank history goes on to the next
+// older register, as "1p... pastes "1, "2 and "3.
+func (m *Model) repeatChange(count int) tea.Cmd {
+	c := m.lastChange
+	switch {
+	case c.command != "":
+		m.statusMessage = "Repeating: " + c.command
+		cmds := []tea.Cmd{clearStatusCmd(2 * time.Second)}
+		for i := 0; i < max(count, 1); i++ {
+			m.createCardMode = c.createMode
+			cmds = append(cmds, m.ExecuteCommand(c.command))
+		}
+		m.createCardMode = m.defaultCreateMode
+		return tea.Batch(cmds...)
+
+	case c.visual > 0:
+		return m.repeatVisual(c)
+
+	case c.input.action != "":
+		input := c.input
+		if count > 0 {
+			input.count = count
+		}
+		if r := input.register; len(r) == 1 && r[0] >= '1' && r[0] < '0'+historySize {
+			input.register = string(r[0] + 1)
+		}
+		return m.runNormal(input)
+	}
+	m.statusMessage = "Nothing to repeat"
+	return clearStatusCmd(2 * time.Second)
+}
+
+// repeatVisual selects as many cards as c applied to, from the focused card
+// on, and applies c to them.
+func (m *Model) repeatVisual(c change) tea.Cmd {
+	if len(m.displayColumns) == 0 {
+		return nil
+	}
+	focus := m.currentFocusedCard()
+	cards := m.displayColumns[m.focusedColumn].Cards
+	if focus == 0 || focus > len(cards) {
+		return nil
+	}
+	m.mode = visualMode
+	m.visualSelectStart = focus - 1
+	m.setCurrentFocusedCard(min(focus+c.visual-1, len(cards)))
+	m.updateVisualSelection()
+	cmd := m.runVisual(c.input)
+	if m.mode == visualMode {
+		m.mode = normalMode
+		m.selected = make(map[string]struct{})
+		m.visualSelectStart = -1
+	}
+	m.setCurrentFocusedCard(min(focus, m.displayColumns[m.focusedColumn].CardCount()))
+	m.ensureFocusedCardIsVisible()
+	return cmd
+}
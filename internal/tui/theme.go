@@ -0,0 +1,110 @@
+// internal/tui/theme.go
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"kanban/internal/fs"
+)
+
+// themeStyles are the styles a theme can color, by their name in the config
+// file. Each style is colored on its own: changing "card" leaves
+// "focused_card" as it is.
+var themeStyles = map[string]*lipgloss.Style{
+	"column_header":                    &columnHeaderStyle,
+	"focused_column_header":            &focusedColumnHeaderStyle,
+	"over_limit_column_header":         &overLimitColumnHeaderStyle,
+	"focused_over_limit_column_header": &focusedOverLimitColumnHeaderStyle,
+	"column_separator":                 &columnSeparatorStyle,
+	"card":                             &cardStyle,
+	"selected_card":                    &selectedCardStyle,
+	"cut_card":                         &cutCardStyle,
+	"focused_card":                     &focusedCardStyle,
+	"focused_selected_card":            &focusedAndSelectedCardStyle,
+	"status_bar":                       &statusBarStyle,
+	"status_normal":                    &statusModeNormal,
+	"status_visual":                    &statusModeVisual,
+	"status_command":                   &statusModeCommand,
+	"status_info":                      &statusInfo,
+	"command_bar":                      &commandBarTextStyle,
+	"search_highlight":                 &searchHighlightStyle,
+	"completion":                       &completionItemStyle,
+	"selected_completion":              &selectedCompletionItemStyle,
+	"tag":                              &tagChipStyle,
+	"assignee":                         &assigneeStyle,
+	"due":                              &dueStyle,
+	"due_soon":                         &dueSoonStyle,
+	"overdue":                          &overdueStyle,
+	"priority":                         &priorityStyle,
+	"popup_hint":                       &popupHintStyle,
+}
+
+// defaultStyles keeps the styles as defined, so that a theme replaces the
+// previous one instead of adding to it.
+var defaultStyles = func() map[string]lipgloss.Style {
+	styles := make(map[string]lipgloss.Style, len(themeStyles))
+	for name, style := range themeStyles {
+		styles[name] = *style
+	}
+	return styles
+}()
+
+// builtinThemes can be chosen without defining them in the config file.
+var builtinThemes = map[string]map[string]fs.StyleColors{
+	"default": {},
+	"light": {
+		"column_header":       {Foreground: "162"},
+		"column_separator":    {Foreground: "250"},
+		"card":                {Border: "248"},
+		"status_info":         {Foreground: "238", Background: "254"},
+		"completion":          {Foreground: "240", Background: "254"},
+		"assignee":            {Foreground: "25"},
+		"due":                 {Foreground: "242"},
+		"due_soon":            {Foreground: "166"},
+		"popup_hint":          {Foreground: "244"},
+		"selected_completion": {Background: "162"},
+	},
+}
+
+// applyTheme restores the default styles and colors them with the theme
+// name, looked up in themes and then in the built-in themes.
+func applyTheme(name string, themes map[string]map[string]fs.StyleColors) error {
+	for style, def := range defaultStyles {
+		*themeStyles[style] = def
+	}
+	if name == "" {
+		return nil
+	}
+	theme, ok := themes[name]
+	if !ok {
+		if theme, ok = builtinThemes[name]; !ok {
+			return fmt.Errorf("unknown theme: %s", name)
+		}
+	}
+
+	var unknown []string
+	for styleName, colors := range theme {
+		style, ok := themeStyles[styleName]
+		if !ok {
+			unknown = append(unknown, styleName)
+			continue
+		}
+		if colors.Foreground != "" {
+			*style = style.Foreground(lipgloss.Color(colors.Foreground))
+		}
+		if colors.Background != "" {
+			*style = style.Background(lipgloss.Color(colors.Background))
+		}
+		if colors.Border != "" {
+			*style = style.BorderForeground(lipgloss.Color(colors.Border))
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("theme %s: unknown style(s): %s", name, strings.Join(unknown, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+// internal/tui/trash.go
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"kanban/internal/card"
+	"kanban/internal/fs"
+)
+
+// trashItem is a card shown in the trash view: either deleted during this
+// session and still pending, or already in the internal trash.
+type trashItem struct {
+	trashed fs.TrashedCard
+	pending bool
+}
+
+func cmdTrash(m *Model, command, args string) tea.Cmd {
+	return m.openTrash()
+}
+
+func (m *Model) openTrash() tea.Cmd {
+	trashed, err := fs.LoadTrash()
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Error reading trash: %v", err)
+		return clearStatusCmd(4 * time.Second)
+	}
+
+	items := make([]trashItem, 0, len(m.board.Trash)+len(trashed))
+	for i := len(m.board.Trash) - 1; i >= 0; i-- {
+		c := m.board.Trash[i]
+		items = append(items, trashItem{
+			trashed: fs.TrashedCard{Card: c, Column: fs.ColumnTitleOf(c.Path)},
+			pending: true,
+		})
+	}
+	for _, t := range trashed {
+		items = append(items, trashItem{trashed: t})
+	}
+
+	m.trashItems = items
+	m.trashCursor = 0
+	m.statusMessage = ""
+	m.mode = trashMode
+	return nil
+}
+
+func (m *Model) updateTrashMode(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q", "ctrl+c":
+		m.mode = normalMode
+		m.trashItems = nil
+
+	case "j", "down":
+		if m.trashCursor < len(m.trashItems)-1 {
+			m.trashCursor++
+		}
+
+	case "k", "up":
+		if m.trashCursor > 0 {
+			m.trashCursor--
+		}
+
+	case "enter", "r":
+		if len(m.trashItems) == 0 {
+			return nil
+		}
+		if m.readOnly {
+			return m.refuseReadOnly()
+		}
+		return m.restoreTrashItem()
+
+	case "X":
+		if len(m.trashItems) == 0 {
+			return nil
+		}
+		if m.readOnly {
+			return m.refuseReadOnly()
+		}
+		return m.purgeTrashItem()
+	}
+	return nil
+}
+
+// restoreTrashItem puts the card under the cursor back at the top of the
+// column it was deleted from, or of the focused column if that one is gone.
+func (m *Model) restoreTrashItem() tea.Cmd {
+	item := m.trashItems[m.trashCursor]
+	c := item.trashed.Card
+	if m.findCard(c.UUID) != nil {
+		m.statusMessage = fmt.Sprintf("'%s' is already on the board", c.Title)
+		return clearStatusCmd(3 * time.Second)
+	}
+
+	dest := m.board.FindColumn(item.trashed.Column)
+	if dest == nil {
+		if len(m.displayColumns) == 0 {
+			return nil
+		}
+		dest = m.sourceColumn(m.focusedColumn)
+	}
+
+	// Why: Undoing the restore of a card from the internal trash does not
+	// delete its file for good: it becomes a pending deletion instead.
+	m.saveStateForUndo("restore")
+	if item.pending {
+		if err := fs.MoveCard(&c, *dest); err != nil {
+			m.history.Drop()
+			return m.writeFailed(err)
+		}
+		m.board.Trash = removeCard(m.board.Trash, c.UUID)
+	} else {
+		restored, err := fs.RestoreTrashedCard(item.trashed, *dest)
+		if err != nil {
+			m.history.Drop()
+			return m.writeFailed(err)
+		}
+		c = restored
+	}
+
+	dest.Cards = append([]card.Card{c}, dest.Cards...)
+	m.removeTrashItem()
+	err := m.writeBoard()
+	m.updateAndResizeFocus()
+	if err != nil {
+		return m.writeFailed(err)
+	}
+	m.statusMessage = fmt.Sprintf("Restored '%s' to '%s'", c.Title, dest.Title)
+	return clearStatusCmd(3 * time.Second)
+}
+
+// purgeTrashItem permanently deletes the card under the cursor.
+func (m *Model) purgeTrashItem() tea.Cmd {
+	item := m.trashItems[m.trashCursor]
+	if err := fs.PurgeTrashedCard(item.trashed); err != nil {
+		return m.writeFailed(err)
+	}
+	if item.pending {
+		m.board.Trash = removeCard(m.board.Trash, item.trashed.Card.UUID)
+	}
+	m.removeTrashItem()
+	m.statusMessage = fmt.Sprintf("Permanently deleted '%s'", item.trashed.Card.Title)
+	return clearStatusCmd(3 * time.Second)
+}
+
+func (m *Model) removeTrashItem() {
+	m.trashItems = append(m.trashItems[:m.trashCursor], m.trashItems[m.trashCursor+1:]...)
+	if m.trashCursor >= len(m.trashItems) && m.trashCursor > 0 {
+		m.trashCursor--
+	}
+}
+
+func removeCard(cards []card.Card, uuid string) []card.Card {
+	kept := make([]card.Card, 0, len(cards))
+	for _, c := range cards {
+		if c.UUID != uuid {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+func renderTrash(m *Model) string {
+	lines := make([]string, len(m.trashItems))
+	for i, item := range m.trashItems {
+		when := "deleted this session"
+		if !item.pending {
+			when = "deleted " + item.trashed.DeletedAt.Local().Format("2006-01-02 15:04")
+		}
+		lines[i] = fmt.Sprintf("%s [%s] %s", item.trashed.Card.Title, item.trashed.Column, popupHintStyle.Render(when))
+	}
+
+	footer := popupHintStyle.Render("enter: restore · X: delete permanently · esc: close")
+	if m.statusMessage != "" {
+		footer = m.statusMessage
+	}
+	return renderListPopup(m, fmt.Sprintf("Trash (%d)", len(m.trashItems)), lines, m.trashCursor, "The trash is empty", footer)
+}
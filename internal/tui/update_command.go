@@ -5,8 +5,6 @@ import (
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
-
-	"kanban/internal/card"
 )
 
 func (m *Model) updateCommandMode(msg tea.Msg) tea.Cmd {
@@ -18,16 +16,18 @@ func (m *Model) updateCommandMode(msg tea.Msg) tea.Cmd {
 		case tea.KeyEscape, tea.KeyCtrlC:
 			m.mode = normalMode
 			m.textInput.Blur()
-			m.createCardMode = "prepend"
+			m.createCardMode = m.defaultCreateMode
 			m.selected = make(map[string]struct{})
-			m.clipboard = []card.Card{}
-			m.isCut = false
+			m.cut = make(map[string]struct{})
 			m.completionMatches = nil
 			m.completionIndex = -1
 			return nil
 		case tea.KeyEnter:
 			commandStr := m.textInput.Value()
 			m.lastCommand = commandStr
+			if isChangeCommand(commandStr) {
+				m.lastChange = change{command: commandStr, createMode: m.createCardMode}
+			}
 			cmd = m.ExecuteCommand(commandStr)
 			if m.mode == commandMode {
 				m.mode = normalMode
@@ -49,7 +49,7 @@ func (m *Model) updateCommandMode(msg tea.Msg) tea.Cmd {
 }
 
 func (m *Model) updateCompletions() {
-	inputValue := m.textInput.Value()
+	_, inputValue := splitRange(m.textInput.Value())
 	parts := strings.Split(inputValue, " ")
 
 	if len(parts) == 0 {
@@ -77,7 +77,7 @@ func (m *Model) updateCompletions() {
 			if len(parts) > 1 {
 				argStr = strings.Join(parts[1:], " ")
 			}
-			candidates = cmdInfo.getCompletions(argStr)
+			candidates = cmdInfo.getCompletions(m, argStr)
 		}
 	}
 
@@ -89,7 +89,7 @@ func (m *Model) updateCompletions() {
 
 	var matches []string
 	for _, c := range candidates {
-		if strings.HasPrefix(c, strings.ToLower(wordToComplete)) {
+		if strings.HasPrefix(strings.ToLower(c), strings.ToLower(wordToComplete)) {
 			matches = append(matches, c)
 		}
 	}
@@ -116,10 +116,10 @@ func (m *Model) cycleCompletion() {
 
 	nextMatch := m.completionMatches[m.completionIndex]
 
-	inputValue := m.textInput.Value()
+	rng, inputValue := splitRange(m.textInput.Value())
 	parts := strings.Split(inputValue, " ")
 	prefixParts := parts[:len(parts)-1]
-	newValue := strings.Join(append(prefixParts, nextMatch), " ")
+	newValue := rng + strings.Join(append(prefixParts, nextMatch), " ")
 
 	m.textInput.SetValue(newValue)
 	m.textInput.SetCursor(len(newValue))
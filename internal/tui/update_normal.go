@@ -2,144 +2,215 @@
 package tui
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"kanban/internal/card"
+	"kanban/internal/column"
 	"kanban/internal/fs"
 )
 
+// normalModeEdits are the actions that change the board.
+var normalModeEdits = map[string]bool{
+	"edit": true, "new-after": true, "new-before": true, "paste": true, "paste-before": true,
+	"delete": true, "priority-up": true, "priority-down": true,
+	"undo": true, "redo": true,
+}
+
+// normalModeChanges are the actions that . repeats.
+var normalModeChanges = map[string]bool{
+	"paste": true, "paste-before": true, "cut": true, "delete": true,
+	"priority-up": true, "priority-down": true, "checklist-toggle": true,
+}
+
 func (m *Model) updateNormalMode(msg tea.Msg) tea.Cmd {
 	keyMsg, ok := msg.(tea.KeyMsg)
 	if !ok {
 		return nil
 	}
 
-	if keyMsg.Type == tea.KeyCtrlP {
-		return m.openFZF()
+	input, done := m.feedKey(m.normalKeys, keyMsg.String())
+	if !done {
+		return nil
+	}
+	return m.runNormal(input)
+}
+
+// runNormal carries out a command typed in normal mode, or repeated with .
+func (m *Model) runNormal(input keyInput) tea.Cmd {
+	action, count := input.action, max(input.count, 1)
+	if m.readOnly && normalModeEdits[action] {
+		return m.refuseReadOnly()
+	}
+	if normalModeChanges[action] {
+		m.lastChange = change{input: input}
 	}
 
-	switch keyMsg.String() {
-	case "q", "ctrl+c":
+	// Why: Remember the lane while focus moves onto a column header, so
+	// that J and K carry on from there.
+	m.focusedLane = m.currentLane()
+
+	switch action {
+	case "quit":
 		if len(m.boardStack) > 0 {
 			return m.popBoard()
 		}
 		return tea.Quit
 
-	case "esc":
+	case "clear":
 		m.selected = make(map[string]struct{})
-		m.clipboard = []card.Card{}
-		m.isCut = false
+		m.cut = make(map[string]struct{})
 
-	case ":":
+	case "command":
 		m.statusMessage = ""
 		m.mode = commandMode
 		m.textInput.SetValue("")
 		m.updateCompletions()
 		return m.textInput.Focus()
 
-	case "/":
+	case "search":
+		m.searchOrigin = m.position()
 		m.statusMessage = ""
 		m.mode = searchMode
 		m.textInput.Prompt = "/"
 		m.textInput.SetValue("")
 		return m.textInput.Focus()
 
-	case "?":
+	case "search-backward":
+		m.searchOrigin = m.position()
 		m.statusMessage = ""
 		m.mode = searchMode
 		m.textInput.Prompt = "?"
 		m.textInput.SetValue("")
 		return m.textInput.Focus()
 
-	case "n":
-		return m.findNext()
+	case "next-match", "prev-match":
+		var cmd tea.Cmd
+		for i := 0; i < count; i++ {
+			if action == "next-match" {
+				cmd = m.findNext()
+			} else {
+				cmd = m.findPrev()
+			}
+		}
+		return cmd
+
+	case "find":
+		return m.openFZF()
+
+	case "record-macro":
+		return m.recordMacro(input.arg)
+
+	case "play-macro":
+		return m.playMacro(input.arg, max(input.count, 1))
+
+	case "set-mark":
+		return m.setMark(input.arg)
 
-	case "N":
-		return m.findPrev()
+	case "goto-mark":
+		return m.goToMark(input.arg)
 
-	case "h", "left":
+	case "jump-back":
+		return m.jumpBack(count)
+
+	case "jump-forward":
+		return m.jumpForward(count)
+
+	case "left":
 		if m.focusedColumn > 0 {
-			m.focusedColumn--
+			lane := m.currentLane()
+			m.focusedColumn = max(m.focusedColumn-count, 0)
 			m.clampFocusedCard()
+			m.keepLane(lane)
 			m.ensureFocusedCardIsVisible()
 		}
 
-	case "l", "right":
+	case "right":
 		if m.focusedColumn < len(m.displayColumns)-1 {
-			m.focusedColumn++
+			lane := m.currentLane()
+			m.focusedColumn = min(m.focusedColumn+count, len(m.displayColumns)-1)
 			m.clampFocusedCard()
+			m.keepLane(lane)
 			m.ensureFocusedCardIsVisible()
 		}
 
-	case "k", "up":
-		currentFocus := m.currentFocusedCard()
-		if currentFocus > 0 {
-			m.setCurrentFocusedCard(currentFocus - 1)
-			m.ensureFocusedCardIsVisible()
-		}
+	case "preview":
+		return m.togglePreview()
 
-	case "j", "down":
-		currentFocus := m.currentFocusedCard()
-		if currentFocus < len(m.displayColumns[m.focusedColumn].Cards) {
-			m.setCurrentFocusedCard(currentFocus + 1)
-			m.ensureFocusedCardIsVisible()
-		}
+	case "preview-half-down":
+		return m.scrollPreview(m.previewHalfPage())
 
-	case "g":
-		if time.Since(m.lastGPress) < 500*time.Millisecond {
-			if len(m.displayColumns[m.focusedColumn].Cards) > 0 {
-				m.setCurrentFocusedCard(1)
-				m.ensureFocusedCardIsVisible()
-			}
-			m.lastGPress = time.Time{}
-		} else {
-			m.lastGPress = time.Now()
+	case "preview-half-up":
+		return m.scrollPreview(-m.previewHalfPage())
+
+	case "preview-down":
+		return m.scrollPreview(1)
+
+	case "preview-up":
+		return m.scrollPreview(-1)
+
+	case "checklist-next":
+		return m.moveChecklistCursor(1)
+
+	case "checklist-prev":
+		return m.moveChecklistCursor(-1)
+
+	case "checklist-toggle":
+		if m.preview {
+			return m.toggleChecklistItem(m.checklistCursor())
 		}
 
-	case "G":
-		numCards := len(m.displayColumns[m.focusedColumn].Cards)
-		if numCards > 0 {
-			m.setCurrentFocusedCard(numCards)
-			m.ensureFocusedCardIsVisible()
+	case "lane-down", "lane-up":
+		delta := 1
+		if action == "lane-up" {
+			delta = -1
 		}
+		var cmd tea.Cmd
+		for i := 0; i < count; i++ {
+			cmd = m.moveLane(delta)
+		}
+		return cmd
 
-	case "f":
-		if time.Since(m.lastGPress) < 500*time.Millisecond { // gf
-			m.lastGPress = time.Time{} // Reset timer
-			currentFocus := m.currentFocusedCard()
-			if currentFocus > 0 {
-				crd := m.displayColumns[m.focusedColumn].Cards[currentFocus-1]
-				if crd.HasLink() {
-					return switchToBoardCmd(crd.Link)
-				}
-				m.statusMessage = "Card has no link"
-				return clearStatusCmd(2 * time.Second)
+	case "up", "down", "first", "last", "block-end", "block-start":
+		m.setCurrentFocusedCard(m.motionTarget(action, input.count))
+		m.ensureFocusedCardIsVisible()
+
+	case "follow-link":
+		currentFocus := m.currentFocusedCard()
+		if currentFocus > 0 {
+			crd := m.displayColumns[m.focusedColumn].Cards[currentFocus-1]
+			if crd.HasLink() {
+				m.pushJump(m.position())
+				return switchToBoardCmd(crd.Link)
 			}
+			m.statusMessage = "Card has no link"
+			return clearStatusCmd(2 * time.Second)
 		}
 
-	case "enter":
+	case "edit":
 		currentFocus := m.currentFocusedCard()
 		if currentFocus > 0 {
 			cardToEdit := m.displayColumns[m.focusedColumn].Cards[currentFocus-1]
-			return openEditor(cardToEdit.Path)
+			return openEditor(m.editor, cardToEdit.Path)
 		}
 
-	case "O":
+	case "new-before":
 		m.statusMessage = ""
 		m.createCardMode = "before"
 		m.mode = commandMode
 		m.textInput.SetValue("new ")
 		return m.textInput.Focus()
 
-	case "o":
+	case "new-after":
 		m.statusMessage = ""
 		m.createCardMode = "after"
 		m.mode = commandMode
 		m.textInput.SetValue("new ")
 		return m.textInput.Focus()
 
-	case "v", "V":
+	case "visual":
 		currentFocus := m.currentFocusedCard()
 		if currentFocus > 0 {
 			m.mode = visualMode
@@ -147,173 +218,239 @@ func (m *Model) updateNormalMode(msg tea.Msg) tea.Cmd {
 			m.updateVisualSelection()
 		}
 
-	case "y":
-		if time.Since(m.lastYPress) < 500*time.Millisecond { // yy
-			currentFocus := m.currentFocusedCard()
-			if currentFocus > 0 {
-				m.clipboard = []card.Card{m.displayColumns[m.focusedColumn].Cards[currentFocus-1]}
-				m.isCut = false
-				m.selected = make(map[string]struct{})
+	case "yank", "cut":
+		return m.operate(input)
+
+	case "paste", "paste-before":
+		return m.paste(input.register, action == "paste-before", false, count)
+
+	case "delete":
+		var cardsToDelete []card.Card
+		if len(m.selected) > 0 {
+			for _, col := range m.board.Columns {
+				for _, c := range col.Cards {
+					if _, isSelected := m.selected[c.UUID]; isSelected {
+						cardsToDelete = append(cardsToDelete, c)
+					}
+				}
 			}
-			m.lastYPress = time.Time{}
-		} else {
-			m.lastYPress = time.Now()
+		} else if m.currentFocusedCard() > 0 {
+			cardIndex := m.currentFocusedCard() - 1
+			cardsToDelete = append(cardsToDelete, m.displayColumns[m.focusedColumn].Cards[cardIndex])
 		}
 
-	case "d":
-		if time.Since(m.lastDPress) < 500*time.Millisecond { // dd
-			currentFocus := m.currentFocusedCard()
-			if currentFocus > 0 {
-				m.clipboard = []card.Card{m.displayColumns[m.focusedColumn].Cards[currentFocus-1]}
-				m.isCut = true
-				m.selected = make(map[string]struct{})
-			}
-			m.lastDPress = time.Time{}
-		} else {
-			m.lastDPress = time.Now()
+		m.saveStateForUndo("delete")
+		cmd := m.deleteCards(cardsToDelete)
+		m.updateDisplayColumns()
+		m.clampFocusedCard()
+		return cmd
+
+	case "priority-up":
+		return m.bumpPriority(-count)
+
+	case "priority-down":
+		return m.bumpPriority(count)
+
+	case "undo":
+		if target, op, ok := m.history.Undo(m.board); ok {
+			return m.restoreBoard(target, op, "Undo")
 		}
+		m.statusMessage = "Nothing to undo"
+		return clearStatusCmd(2 * time.Second)
 
-	case "p", "P":
-		if len(m.clipboard) == 0 {
-			return nil
+	case "redo":
+		if target, op, ok := m.history.Redo(m.board); ok {
+			return m.restoreBoard(target, op, "Redo")
 		}
+		m.statusMessage = "Nothing to redo"
+		return clearStatusCmd(2 * time.Second)
 
-		m.saveStateForUndo()
-		destCol := m.displayColumns[m.focusedColumn]
+	case "repeat":
+		return m.repeatChange(input.count)
+	}
+	return nil
+}
 
-		insertIndex := 0
-		currentFocus := m.currentFocusedCard()
-		if currentFocus > 0 {
-			if keyMsg.String() == "p" {
-				insertIndex = currentFocus
-			} else {
-				insertIndex = currentFocus - 1
-			}
+// paste inserts the cards of register name, "" for the unnamed one, count
+// times after (or before) the focused card. Cards of the pending cut are moved
+// and then copied for the other count-1 times, the others are copied. The
+// register keeps its cards for the next paste. force ignores the
+// destination's WIP limit.
+func (m *Model) paste(name string, before, force bool, count int) tea.Cmd {
+	if name == "" {
+		name = unnamedRegister
+	}
+	if !isRegisterName(name) {
+		m.statusMessage = fmt.Sprintf("Invalid register: %s", name)
+		return clearStatusCmd(2 * time.Second)
+	}
+	cards := m.registerCards(name)
+	if len(cards) == 0 {
+		if name == unnamedRegister {
+			return nil
 		}
+		m.statusMessage = fmt.Sprintf("Register %s is empty", strings.ToLower(name))
+		return clearStatusCmd(2 * time.Second)
+	}
 
-		if insertIndex > len(destCol.Cards) {
-			insertIndex = len(destCol.Cards)
+	// Why: Only cards still on the board can be moved; the others are
+	// recreated from the register.
+	moved := make(map[string]struct{})
+	for _, c := range cards {
+		if _, isCut := m.cut[c.UUID]; isCut && m.findCard(c.UUID) != nil {
+			moved[c.UUID] = struct{}{}
 		}
+	}
 
-		if m.isCut {
-			clipboardUUIDs := make(map[string]struct{}, len(m.clipboard))
-			for i := range m.clipboard {
-				c := &m.clipboard[i]
-				clipboardUUIDs[c.UUID] = struct{}{}
-				fs.MoveCard(c, *destCol)
-			}
+	destCol := m.sourceColumn(m.focusedColumn)
+	if !force && m.pasteExceedsWIPLimit(destCol, cards, moved, count) {
+		return m.wipLimitMessage(destCol, "paste")
+	}
 
-			// Remove cut cards from all columns that are NOT the destination.
-			for i := range m.board.Columns {
-				col := &m.board.Columns[i]
-				if col.Title == destCol.Title {
-					continue
-				}
-				keptCards := make([]card.Card, 0, len(col.Cards))
-				for _, c := range col.Cards {
-					if _, isCut := clipboardUUIDs[c.UUID]; !isCut {
-						keptCards = append(keptCards, c)
-					}
-				}
-				col.Cards = keptCards
-			}
-			if m.board.Archived.Title != destCol.Title {
-				keptArchived := make([]card.Card, 0, len(m.board.Archived.Cards))
-				for _, c := range m.board.Archived.Cards {
-					if _, isCut := clipboardUUIDs[c.UUID]; !isCut {
-						keptArchived = append(keptArchived, c)
-					}
-				}
-				m.board.Archived.Cards = keptArchived
-			}
+	m.saveStateForUndo("paste")
+
+	insertIndex := 0
+	currentFocus := m.currentFocusedCard()
+	if currentFocus > 0 {
+		if before {
+			insertIndex = currentFocus - 1
+		} else {
+			insertIndex = currentFocus
+		}
+	}
 
-			// Rebuild the destination column's card list, inserting the clipboard.
-			// This correctly handles cutting and pasting within the same column.
-			newDestCards := make([]card.Card, 0, len(destCol.Cards)+len(m.clipboard))
+	// Why: Pasting into another swimlane moves the cards into that lane.
+	destLane, inLane := m.pasteLane()
+	if inLane {
+		insertIndex = m.laneInsertIndex(before)
+	} else {
+		insertIndex = m.sourceInsertIndex(m.focusedColumn, insertIndex)
+	}
+	if insertIndex > len(destCol.Cards) {
+		insertIndex = len(destCol.Cards)
+	}
 
-			for i := 0; i < insertIndex; i++ {
-				c := destCol.Cards[i]
-				if _, isCut := clipboardUUIDs[c.UUID]; !isCut {
-					newDestCards = append(newDestCards, c)
-				}
+	var writeErr error
+	pasted := make([]card.Card, 0, len(cards))
+	for i := range cards {
+		c := &cards[i]
+		if _, isCut := moved[c.UUID]; !isCut {
+			copies, err := m.copyCards([]card.Card{*c}, destCol, 1, destLane, inLane)
+			if err != nil && writeErr == nil {
+				writeErr = err
 			}
+			pasted = append(pasted, copies...)
+			continue
+		}
+		if err := fs.MoveCard(c, *destCol); err != nil && writeErr == nil {
+			writeErr = err
+		}
+		if inLane {
+			if err := m.assignLane(c, destLane); err != nil && writeErr == nil {
+				writeErr = err
+			}
+		}
+		pasted = append(pasted, *c)
+	}
 
-			newDestCards = append(newDestCards, m.clipboard...)
-
-			for i := insertIndex; i < len(destCol.Cards); i++ {
-				c := destCol.Cards[i]
-				if _, isCut := clipboardUUIDs[c.UUID]; !isCut {
-					newDestCards = append(newDestCards, c)
-				}
+	// Remove moved cards from all columns that are NOT the destination.
+	if len(moved) > 0 {
+		for i := range m.board.Columns {
+			col := &m.board.Columns[i]
+			if col.Title == destCol.Title {
+				continue
 			}
-			destCol.Cards = newDestCards
-		} else {
-			var newCards []card.Card
-			for _, c := range m.clipboard {
-				newCard, err := fs.CopyCard(c, *destCol)
-				if err == nil {
-					newCards = append(newCards, newCard)
+			keptCards := make([]card.Card, 0, len(col.Cards))
+			for _, c := range col.Cards {
+				if _, isCut := moved[c.UUID]; !isCut {
+					keptCards = append(keptCards, c)
 				}
 			}
-			if len(newCards) > 0 {
-				destCol.Cards = append(destCol.Cards[:insertIndex], append(newCards, destCol.Cards[insertIndex:]...)...)
-			}
+			col.Cards = keptCards
 		}
-		m.isCut = false
-		m.clipboard = []card.Card{}
-		m.clampFocusedCard()
-		m.ensureFocusedCardIsVisible()
-
-	case "delete", "backspace":
-		var cardsToDelete []card.Card
-		if len(m.selected) > 0 {
-			for _, col := range m.board.Columns {
-				for _, c := range col.Cards {
-					if _, isSelected := m.selected[c.UUID]; isSelected {
-						cardsToDelete = append(cardsToDelete, c)
-					}
+		if m.board.Archived.Title != destCol.Title {
+			keptArchived := make([]card.Card, 0, len(m.board.Archived.Cards))
+			for _, c := range m.board.Archived.Cards {
+				if _, isCut := moved[c.UUID]; !isCut {
+					keptArchived = append(keptArchived, c)
 				}
 			}
-		} else if m.currentFocusedCard() > 0 {
-			cardIndex := m.currentFocusedCard() - 1
-			cardsToDelete = append(cardsToDelete, m.displayColumns[m.focusedColumn].Cards[cardIndex])
+			m.board.Archived.Cards = keptArchived
 		}
+	}
 
-		m.saveStateForUndo()
-		m.deleteCards(cardsToDelete)
-		m.updateDisplayColumns()
-		m.clampFocusedCard()
+	// Rebuild the destination column's card list, inserting the pasted
+	// cards. This correctly handles cutting and pasting within the same column.
+	newDestCards := make([]card.Card, 0, len(destCol.Cards)+len(pasted)*count)
 
-	case "u":
-		if newState, ok := m.history.Undo(m.board); ok {
-			m.board = newState
-			fs.WriteBoard(m.board)
-			m.updateAndResizeFocus()
-			m.statusMessage = "Undo successful"
-			return clearStatusCmd(2 * time.Second)
+	for i := 0; i < insertIndex; i++ {
+		c := destCol.Cards[i]
+		if _, isCut := moved[c.UUID]; !isCut {
+			newDestCards = append(newDestCards, c)
 		}
-		m.statusMessage = "Nothing to undo"
-		return clearStatusCmd(2 * time.Second)
+	}
 
-	case "ctrl+r":
-		if newState, ok := m.history.Redo(m.board); ok {
-			m.board = newState
-			fs.WriteBoard(m.board)
-			m.updateAndResizeFocus()
-			m.statusMessage = "Redo successful"
-			return clearStatusCmd(2 * time.Second)
-		}
-		m.statusMessage = "Nothing to redo"
-		return clearStatusCmd(2 * time.Second)
+	extra, err := m.copyCards(pasted, destCol, count-1, lane{}, false)
+	if err != nil && writeErr == nil {
+		writeErr = err
+	}
+	newDestCards = append(newDestCards, pasted...)
+	newDestCards = append(newDestCards, extra...)
 
-	case ".":
-		if m.lastCommand != "" {
-			m.statusMessage = "Repeating: " + m.lastCommand
-			m.ExecuteCommand(m.lastCommand)
-			return clearStatusCmd(2 * time.Second)
+	for i := insertIndex; i < len(destCol.Cards); i++ {
+		c := destCol.Cards[i]
+		if _, isCut := moved[c.UUID]; !isCut {
+			newDestCards = append(newDestCards, c)
 		}
-		m.statusMessage = "No command to repeat"
-		return clearStatusCmd(2 * time.Second)
+	}
+	destCol.Cards = newDestCards
+
+	for uuid := range moved {
+		delete(m.cut, uuid)
+	}
+	if err := m.writeBoard(); writeErr == nil {
+		writeErr = err
+	}
+	m.updateDisplayColumns()
+	m.clampFocusedCard()
+	m.ensureFocusedCardIsVisible()
+	if writeErr != nil {
+		return m.writeFailed(writeErr)
 	}
 	return nil
 }
+
+// copyCards copies cards into destCol rounds times, moving the copies into
+// lane l if inLane, and returns the copies that could be made.
+func (m *Model) copyCards(cards []card.Card, destCol *column.Column, rounds int, l lane, inLane bool) ([]card.Card, error) {
+	var copies []card.Card
+	var firstErr error
+	for r := 0; r < rounds; r++ {
+		for _, c := range cards {
+			newCard, err := fs.CopyCard(c, *destCol)
+			if err == nil && inLane {
+				err = m.assignLane(&newCard, l)
+			}
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			if newCard.UUID != "" {
+				copies = append(copies, newCard)
+			}
+		}
+	}
+	return copies, firstErr
+}
+
+// pasteExceedsWIPLimit reports whether pasting cards count times into col
+// would break its WIP limit. Only the first paste of the moved cards moves
+// them; the others are copies.
+func (m *Model) pasteExceedsWIPLimit(col *column.Column, cards []card.Card, moved map[string]struct{}, count int) bool {
+	n := len(cards) * (count - 1)
+	for _, c := range cards {
+		if _, isCut := moved[c.UUID]; !isCut || indexOfCard(col.Cards, c.UUID) == len(col.Cards) {
+			n++
+		}
+	}
+	return col.WouldExceedLimit(n)
+}
@@ -29,6 +29,7 @@ func (m *Model) updateSearchMode(msg tea.Msg) tea.Cmd {
 			m.lastSearchQuery = query
 			m.lastSearchDirection = m.textInput.Prompt
 			cmd = m.jumpToFirstResult(true)
+			m.recordJump(m.searchOrigin)
 			m.mode = normalMode
 			m.textInput.Blur()
 			return cmd
@@ -2,103 +2,97 @@
 package tui
 
 import (
-	"time"
-
 	tea "github.com/charmbracelet/bubbletea"
 	"kanban/internal/card"
 )
 
+// visualModeEdits are the actions that change the board.
+var visualModeEdits = map[string]bool{
+	"delete": true, "priority-up": true, "priority-down": true,
+}
+
+// visualModeChanges are the actions that . repeats, on as many cards.
+var visualModeChanges = map[string]bool{
+	"cut": true, "delete": true, "priority-up": true, "priority-down": true,
+}
+
 func (m *Model) updateVisualMode(msg tea.Msg) tea.Cmd {
 	keyMsg, ok := msg.(tea.KeyMsg)
 	if !ok {
 		return nil
 	}
 
-	switch keyMsg.String() {
-	case "q", "ctrl+c":
+	input, done := m.feedKey(m.visualKeys, keyMsg.String())
+	if !done {
+		return nil
+	}
+	return m.runVisual(input)
+}
+
+// runVisual carries out a command typed in visual mode, or repeated with .
+func (m *Model) runVisual(input keyInput) tea.Cmd {
+	action := input.action
+	if m.readOnly && visualModeEdits[action] {
+		return m.refuseReadOnly()
+	}
+	if visualModeChanges[action] && len(m.selected) > 0 {
+		m.lastChange = change{input: input, visual: len(m.selected)}
+	}
+
+	switch action {
+	case "quit":
 		return tea.Quit
 
-	case "esc", "v", "V":
+	case "exit":
 		m.mode = normalMode
 		m.selected = make(map[string]struct{})
 		m.visualSelectStart = -1
 
-	case "h", "left", "l", "right":
+	case "left", "right":
 		m.mode = normalMode
 		m.selected = make(map[string]struct{})
 		m.visualSelectStart = -1
 
-	case "j", "down":
-		currentFocus := m.currentFocusedCard()
-		if currentFocus < len(m.displayColumns[m.focusedColumn].Cards) {
-			m.setCurrentFocusedCard(currentFocus + 1)
+	case "up", "down", "first", "last", "block-end", "block-start":
+		// Why: The selection stops at the first card.
+		if target := max(m.motionTarget(action, input.count), 1); target <= len(m.displayColumns[m.focusedColumn].Cards) {
+			m.setCurrentFocusedCard(target)
 			m.updateVisualSelection()
 			m.ensureFocusedCardIsVisible()
 		}
 
-	case "k", "up":
-		currentFocus := m.currentFocusedCard()
-		if currentFocus > 1 { // Stop at the first card
-			m.setCurrentFocusedCard(currentFocus - 1)
-			m.updateVisualSelection()
-			m.ensureFocusedCardIsVisible()
-		}
+	case "record-macro":
+		return m.recordMacro(input.arg)
 
-	case "g":
-		if time.Since(m.lastGPress) < 500*time.Millisecond {
-			if len(m.displayColumns[m.focusedColumn].Cards) > 0 {
-				m.setCurrentFocusedCard(1)
-				m.updateVisualSelection()
-				m.ensureFocusedCardIsVisible()
-			}
-			m.lastGPress = time.Time{}
-		} else {
-			m.lastGPress = time.Now()
-		}
+	case "play-macro":
+		return m.playMacro(input.arg, max(input.count, 1))
 
-	case "G":
-		numCards := len(m.displayColumns[m.focusedColumn].Cards)
-		if numCards > 0 {
-			m.setCurrentFocusedCard(numCards)
-			m.updateVisualSelection()
-			m.ensureFocusedCardIsVisible()
-		}
-
-	case ":":
+	case "command":
 		m.statusMessage = ""
 		m.mode = commandMode
-		m.textInput.SetValue("")
+		m.textInput.SetValue(visualRange)
+		m.textInput.SetCursor(len(visualRange))
 		return m.textInput.Focus()
 
-	case "y":
-		if len(m.selected) > 0 {
-			m.clipboard = []card.Card{}
-			m.isCut = false
-			for _, c := range m.displayColumns[m.focusedColumn].Cards {
-				if _, ok := m.selected[c.UUID]; ok {
-					m.clipboard = append(m.clipboard, c)
-				}
+	case "yank", "cut":
+		var cards []card.Card
+		for _, c := range m.displayColumns[m.focusedColumn].Cards {
+			if _, ok := m.selected[c.UUID]; ok {
+				cards = append(cards, c)
 			}
 		}
 		m.mode = normalMode
 		m.selected = make(map[string]struct{})
 		m.visualSelectStart = -1
+		return m.storeRegister(input.register, cards, action == "cut")
 
-	case "d":
-		if len(m.selected) > 0 {
-			m.clipboard = []card.Card{}
-			m.isCut = true
-			for _, c := range m.displayColumns[m.focusedColumn].Cards {
-				if _, ok := m.selected[c.UUID]; ok {
-					m.clipboard = append(m.clipboard, c)
-				}
-			}
-		}
-		m.mode = normalMode
-		m.selected = make(map[string]struct{})
-		m.visualSelectStart = -1
+	case "priority-up":
+		return m.bumpPriority(-1)
+
+	case "priority-down":
+		return m.bumpPriority(1)
 
-	case "delete", "backspace":
+	case "delete":
 		var cardsToDelete []card.Card
 		if len(m.selected) > 0 {
 			for _, c := range m.displayColumns[m.focusedColumn].Cards {
@@ -107,12 +101,13 @@ func (m *Model) updateVisualMode(msg tea.Msg) tea.Cmd {
 				}
 			}
 		}
-		m.saveStateForUndo()
-		m.deleteCards(cardsToDelete)
+		m.saveStateForUndo("delete")
+		cmd := m.deleteCards(cardsToDelete)
 		m.updateDisplayColumns()
 		m.clampFocusedCard()
 		m.mode = normalMode
 		m.visualSelectStart = -1
+		return cmd
 	}
 	return nil
 }
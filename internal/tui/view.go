@@ -3,9 +3,11 @@ package tui
 
 import (
 	"fmt"
+	"hash/fnv"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"kanban/internal/card"
@@ -22,6 +24,14 @@ var (
 					Foreground(lipgloss.Color("231")).
 					Background(lipgloss.Color("205"))
 
+	overLimitColumnHeaderStyle = columnHeaderStyle.Copy().
+					Foreground(lipgloss.Color("196")).
+					Bold(true)
+
+	focusedOverLimitColumnHeaderStyle = focusedColumnHeaderStyle.Copy().
+						Background(lipgloss.Color("196")).
+						Bold(true)
+
 	cardStyle = lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
 			BorderForeground(lipgloss.Color("240")).
@@ -47,6 +57,9 @@ var (
 	columnStyle = lipgloss.NewStyle().
 			Padding(0, columnPaddingHorizontal)
 
+	columnSeparatorStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("238"))
+
 	statusBarStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("231")).
 			Background(lipgloss.Color("#3e6452"))
@@ -84,12 +97,43 @@ var (
 	selectedCompletionItemStyle = completionItemStyle.Copy().
 					Foreground(lipgloss.Color("231")).
 					Background(lipgloss.Color("205"))
+
+	tagChipStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("232"))
+
+	assigneeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("117"))
+
+	dueStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("245"))
+
+	dueSoonStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("220")).
+			Bold(true)
+
+	overdueStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			Bold(true)
+
+	priorityStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("232")).
+			Bold(true)
+
+	// priorityColors is indexed by card.PriorityRank.
+	priorityColors = []lipgloss.Color{"196", "208", "220", "244"}
+
+	// tagColors are assigned to tags by hashing their name so a tag keeps
+	// its color across sessions.
+	tagColors = []lipgloss.Color{"39", "170", "214", "76", "203", "141", "45", "180"}
 )
 
 func renderBoard(m *Model, height int) string {
 	if m.width <= 0 || len(m.displayColumns) == 0 || height <= 0 {
 		return ""
 	}
+	if m.laneField != "" {
+		return renderLaneBoard(m, height)
+	}
 
 	numColumns := len(m.displayColumns)
 	separator := "│"
@@ -98,7 +142,7 @@ func renderBoard(m *Model, height int) string {
 		numSeparators = 0
 	}
 
-	availableWidth := m.width - numSeparators
+	availableWidth := m.boardWidth() - numSeparators
 	baseColumnWidth := availableWidth / numColumns
 	remainder := availableWidth % numColumns
 
@@ -112,11 +156,10 @@ func renderBoard(m *Model, height int) string {
 	}
 
 	var parts []string
-	separatorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
 	for i, s := range renderedColumns {
 		parts = append(parts, s)
 		if i < len(renderedColumns)-1 {
-			parts = append(parts, separatorStyle.Render(separator))
+			parts = append(parts, columnSeparatorStyle.Render(separator))
 		}
 	}
 
@@ -127,12 +170,7 @@ func renderColumn(c column.Column, m *Model, columnIndex int, width int, height
 	isColumnFocused := m.focusedColumn == columnIndex
 	isHeaderFocused := isColumnFocused && m.currentFocusedCard() == 0
 
-	header := fmt.Sprintf("%s %d", c.Title, c.CardCount())
-
-	headerStyle := columnHeaderStyle
-	if isHeaderFocused {
-		headerStyle = focusedColumnHeaderStyle
-	}
+	header, headerStyle := columnHeader(m, columnIndex, isHeaderFocused)
 
 	headerContentWidth := width - columnStyle.GetHorizontalPadding() - headerStyle.GetHorizontalPadding()
 	renderedHeader := headerStyle.Copy().Width(headerContentWidth).Render(header)
@@ -166,6 +204,28 @@ func renderColumn(c column.Column, m *Model, columnIndex int, width int, height
 	return columnStyle.Copy().Width(width).Height(height).Render(columnContent)
 }
 
+// columnHeader returns the header text of display column i, showing
+// count/limit for columns with a WIP limit, and the style to draw it with.
+func columnHeader(m *Model, i int, focused bool) (string, lipgloss.Style) {
+	col := m.displayColumns[i]
+	src := m.sourceColumn(i)
+
+	header := fmt.Sprintf("%s %d", col.Title, col.CardCount())
+	if src.Limit > 0 {
+		header = fmt.Sprintf("%s %d/%d", col.Title, src.CardCount(), src.Limit)
+	}
+
+	switch {
+	case focused && src.OverLimit():
+		return header, focusedOverLimitColumnHeaderStyle
+	case focused:
+		return header, focusedColumnHeaderStyle
+	case src.OverLimit():
+		return header, overLimitColumnHeaderStyle
+	}
+	return header, columnHeaderStyle
+}
+
 func renderCard(c card.Card, m *Model, columnIndex, cardIndex int, contentWidth int) string {
 	isFocused := m.focusedColumn == columnIndex && m.currentFocusedCard() == cardIndex+1
 	_, isSelected := m.selected[c.UUID]
@@ -202,15 +262,65 @@ func renderCard(c card.Card, m *Model, columnIndex, cardIndex int, contentWidth
 		}
 	}
 
+	if c.HasContent() && !isSelected && m.mode != searchMode {
+		style = style.Foreground(lipgloss.Color("81"))
+	}
+
+	return style.Copy().Width(contentWidth).Render(cardBody(c, title))
+}
+
+// cardBody lays out everything shown inside a card's border. It is shared
+// with getCardRenderHeight so scrolling agrees with what is drawn.
+func cardBody(c card.Card, title string) string {
 	if c.HasLink() {
 		title = "🔗 " + title
 	}
+	if badges := renderCardBadges(c); badges != "" {
+		title += "\n" + badges
+	}
+	return title
+}
 
-	if c.HasContent() && !isSelected && m.mode != searchMode {
-		style = style.Foreground(lipgloss.Color("81"))
+func renderCardBadges(c card.Card) string {
+	var chips []string
+	if rank := c.PriorityRank(); rank < len(priorityColors) {
+		chips = append(chips, priorityStyle.Copy().Background(priorityColors[rank]).Render(c.Priority))
+	}
+	if due := renderDue(c); due != "" {
+		chips = append(chips, due)
 	}
+	if progress := renderChecklistBadge(c); progress != "" {
+		chips = append(chips, progress)
+	}
+	if c.Assignee != "" {
+		chips = append(chips, assigneeStyle.Render("@"+c.Assignee))
+	}
+	for _, tag := range c.Tags {
+		chips = append(chips, renderTagChip(tag))
+	}
+	return strings.Join(chips, " ")
+}
 
-	return style.Copy().Width(contentWidth).Render(title)
+func renderDue(c card.Card) string {
+	days, ok := c.DueIn(time.Now())
+	if !ok {
+		return ""
+	}
+	switch {
+	case days < 0:
+		return overdueStyle.Render("due " + c.Due)
+	case days <= dueSoonDays:
+		return dueSoonStyle.Render("due " + c.Due)
+	default:
+		return dueStyle.Render("due " + c.Due)
+	}
+}
+
+func renderTagChip(tag string) string {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(tag)))
+	color := tagColors[h.Sum32()%uint32(len(tagColors))]
+	return tagChipStyle.Copy().Background(color).Render(tag)
 }
 
 func renderCompletionMenu(m *Model) string {
@@ -254,6 +364,23 @@ func renderStatusBar(m *Model) string {
 		displayPath = "~" + strings.TrimPrefix(fullPath, home)
 	}
 	fileInfo := statusInfo.Render(" " + displayPath + " ")
+	if m.filter != nil {
+		fileInfo += statusInfo.Render("[filter: " + m.filter.query + "] ")
+	}
+	if m.laneField != "" {
+		fileInfo += statusInfo.Render("[lanes: " + m.laneField + "] ")
+	}
+	if m.recording != "" {
+		fileInfo += statusInfo.Render("[recording @" + m.recording + "] ")
+	}
+	if m.readOnly {
+		fileInfo += statusInfo.Render("[read-only] ")
+	}
+	if m.reloadConflict {
+		fileInfo += statusInfo.Render("[changed on disk] ")
+	} else if m.unsaved {
+		fileInfo += statusInfo.Render("[unsaved] ")
+	}
 
 	var progressInfo string
 	if len(m.displayColumns) > 0 && m.focusedColumn < len(m.displayColumns) {
@@ -299,9 +426,59 @@ func renderStatusBar(m *Model) string {
 			commandLine = "" // Render an empty line to reserve space
 		}
 	}
+	// Why: Show the count and keys of a command being typed, like Vim's
+	// showcmd.
+	if pending := m.input.pending(); pending != "" && (m.mode == normalMode || m.mode == visualMode) {
+		gap := m.width - lipgloss.Width(commandLine) - lipgloss.Width(pending) - 1
+		if gap > 0 {
+			commandLine += strings.Repeat(" ", gap) + pending
+		}
+	}
 
 	if completionMenu != "" {
 		return lipgloss.JoinVertical(lipgloss.Left, statusLine, completionMenu, commandLine)
 	}
 	return lipgloss.JoinVertical(lipgloss.Left, statusLine, commandLine)
 }
+
+var popupHintStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+// renderListPopup draws a centered popup with a scrolling list in which the
+// line at cursor is highlighted. Lines are cut to the popup width.
+func renderListPopup(m *Model, title string, lines []string, cursor int, empty, footer string) string {
+	popupWidth := int(float64(m.width) * 0.8)
+	if popupWidth > 120 {
+		popupWidth = 120
+	}
+	popupHeight := int(float64(m.height) * 0.6)
+	footer = lipgloss.NewStyle().Width(popupWidth - 4).Render(footer)
+	listHeight := popupHeight - 2 - lipgloss.Height(footer)
+	if listHeight < 1 {
+		listHeight = 1
+	}
+
+	first := 0
+	if cursor >= listHeight {
+		first = cursor - listHeight + 1
+	}
+
+	lineStyle := lipgloss.NewStyle().MaxWidth(popupWidth - 4)
+	var b strings.Builder
+	if len(lines) == 0 {
+		b.WriteString(popupHintStyle.Render("  " + empty))
+	}
+	for i := first; i < len(lines) && i < first+listHeight; i++ {
+		if i == cursor {
+			b.WriteString(fzfSelectedItemStyle.Render(lineStyle.Render("> " + lines[i])))
+		} else {
+			b.WriteString(lineStyle.Render("  " + lines[i]))
+		}
+		b.WriteRune('\n')
+	}
+
+	list := lipgloss.NewStyle().Height(listHeight).Render(strings.TrimSuffix(b.String(), "\n"))
+	content := lipgloss.JoinVertical(lipgloss.Left, title, list, footer)
+	popup := fzfPopupStyle.Width(popupWidth).Height(popupHeight).Render(content)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, popup)
+}
@@ -0,0 +1,73 @@
+// Package watch detects changes to a board's files by polling their
+// modification times and sizes.
+package watch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	kfs "kanban/internal/fs"
+)
+
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Snapshot records kanban.md and every card file under the data directory.
+type Snapshot map[string]stamp
+
+// Scan takes a snapshot of the board in the current directory.
+func Scan() (Snapshot, error) {
+	snap := make(Snapshot)
+
+	if info, err := os.Stat(kfs.BoardFileName); err == nil {
+		snap[kfs.BoardFileName] = stamp{info.ModTime(), info.Size()}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	err := filepath.WalkDir(kfs.DataDirName, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		// Why: Hidden directories hold application bookkeeping, not cards.
+		if d.IsDir() && path != kfs.DataDirName && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || filepath.Ext(path) != ".md" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // Removed while walking
+		}
+		snap[path] = stamp{info.ModTime(), info.Size()}
+		return nil
+	})
+	return snap, err
+}
+
+// Changed lists the paths that were added, removed or modified in next.
+func (s Snapshot) Changed(next Snapshot) []string {
+	var changed []string
+	for path, st := range next {
+		if prev, ok := s[path]; !ok || !prev.modTime.Equal(st.modTime) || prev.size != st.size {
+			changed = append(changed, path)
+		}
+	}
+	for path := range s {
+		if _, ok := next[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}